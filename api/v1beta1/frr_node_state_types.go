@@ -32,6 +32,50 @@ type FRRNodeStateStatus struct {
 	LastConversionResult string `json:"lastConversionResult,omitempty"`
 	// LastReloadResult represents the status of the last configuration update operation by FRR, contains "success" or an error.
 	LastReloadResult string `json:"lastReloadResult,omitempty"`
+	// RolledBack tells if FRR was reverted to the last configuration reloaded successfully,
+	// because the latest one kept failing to reload. The error is reported in LastReloadResult.
+	RolledBack bool `json:"rolledBack,omitempty"`
+	// ConfigDrift contains the differences between the configuration rendered out of the
+	// FRRConfigurations and the one FRR is running with, regardless of the order of the lines.
+	// The lines of the rendered configuration missing from the running one are prefixed with "-",
+	// and the lines of the running configuration not part of the rendered one with "+".
+	ConfigDrift []string `json:"configDrift,omitempty"`
+	// Conditions contains the ConfigDrift condition, true when the running configuration
+	// differs from the rendered one.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ReloadHistory contains the latest attempts to reload the configuration of FRR, the oldest first.
+	ReloadHistory []ReloadAttempt `json:"reloadHistory,omitempty"`
+	// DisabledNeighbors is the list of the neighbors whose session is administratively shut down
+	// in the running config, in the form "address" or "address vrf name".
+	DisabledNeighbors []string `json:"disabledNeighbors,omitempty"`
+	// IgnoredConfigurations is the list of the FRRConfigurations selecting the node that are ignored
+	// because they are not allowed by the cluster policy, in the form "namespace/name: reason".
+	IgnoredConfigurations []string `json:"ignoredConfigurations,omitempty"`
+}
+
+// ConditionConfigDrift is the type of the condition telling if the running configuration
+// differs from the one rendered out of the FRRConfigurations.
+const ConditionConfigDrift = "ConfigDrift"
+
+// ReloadAttempt describes an attempt to reload the configuration of FRR.
+type ReloadAttempt struct {
+	// Time is when the attempt started.
+	Time metav1.Time `json:"time"`
+	// Trigger is what caused the attempt: a change of the configuration, a retry
+	// after a failure, a rollback to the last good configuration or a drift of the
+	// running configuration.
+	// +kubebuilder:validation:Enum=ConfigChange;Retry;Rollback;DriftHeal
+	Trigger string `json:"trigger"`
+	// Duration is the time taken to render and reload the configuration.
+	Duration metav1.Duration `json:"duration"`
+	// Result contains "success" or an error.
+	Result string `json:"result"`
+	// LinesAdded is the number of lines added to the running configuration.
+	LinesAdded int `json:"linesAdded,omitempty"`
+	// LinesRemoved is the number of lines removed from the running configuration.
+	LinesRemoved int `json:"linesRemoved,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -37,6 +37,12 @@ type FRRConfigurationSpec struct {
 	// When it is not specified all nodes will attempt to apply this config.
 	// +optional
 	NodeSelector metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	// Priority is used to resolve the conflicts with the other configurations selecting
+	// the same node. A scalar field set to different values by multiple configurations
+	// takes the value of the one with the highest priority, and the conflict is reported
+	// as a warning. Conflicts between configurations with the same priority are errors.
+	// +optional
+	Priority int `json:"priority,omitempty"`
 }
 
 // RawConfig is a snippet of raw frr configuration that gets appended to the
@@ -50,6 +56,42 @@ type RawConfig struct {
 	// Config is a raw FRR configuration to be appended to the configuration
 	// rendered via the k8s api.
 	Config string `json:"rawConfig,omitempty"`
+
+	// Scoped is a list of raw FRR configuration snippets injected in a given section
+	// of the rendered configuration, instead of being appended to the bottom of it.
+	// Snippets targeting the same section are injected in the order given by the priority.
+	// +optional
+	Scoped []ScopedRawConfig `json:"scoped,omitempty"`
+}
+
+// ScopedRawConfig is a snippet of raw frr configuration injected in the section
+// of the rendered configuration related to a router, to one of its address families
+// or to one of its neighbors.
+type ScopedRawConfig struct {
+	// ASN is the AS number of the router the snippet is injected into.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4294967295
+	ASN uint32 `json:"asn"`
+
+	// VRF is the vrf of the router the snippet is injected into.
+	// +optional
+	VRF string `json:"vrf,omitempty"`
+
+	// AddressFamily, when set, injects the snippet into the unicast address family
+	// of the router. Combined with the neighbor, it injects the snippet where the
+	// neighbor is activated for the address family.
+	// +kubebuilder:validation:Enum=ipv4;ipv6
+	// +optional
+	AddressFamily string `json:"addressFamily,omitempty"`
+
+	// Neighbor is the address of the neighbor of the router the snippet refers to.
+	// When set, the snippet is injected after the configuration of the neighbor.
+	// +optional
+	Neighbor string `json:"neighbor,omitempty"`
+
+	// Config is the raw FRR configuration to be injected.
+	// +kubebuilder:validation:MinLength=1
+	Config string `json:"rawConfig"`
 }
 
 // BGPConfig is the configuration related to the BGP protocol.
@@ -67,7 +109,12 @@ type Router struct {
 	// ASN is the AS number to use for the local end of the session.
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=4294967295
+	// +optional
 	ASN uint32 `json:"asn"`
+	// ASNFrom is a node variable expression, such as ${node.labels['rack-asn']}, resolving
+	// to the AS number to use for the local end of the session. ASN and ASNFrom are mutually exclusive, and one of them must be set.
+	// +optional
+	ASNFrom string `json:"asnFrom,omitempty"`
 	// ID is the BGP router ID
 	// +optional
 	ID string `json:"id,omitempty"`
@@ -80,6 +127,105 @@ type Router struct {
 	// Prefixes is the list of prefixes we want to advertise from this router instance.
 	// +optional
 	Prefixes []string `json:"prefixes,omitempty"`
+	// ServiceSelector selects the services whose LoadBalancer ingress IPs and ExternalIPs
+	// are added to the prefixes advertised from this router instance, as /32 or /128 prefixes.
+	// An empty selector selects all the services. Services with externalTrafficPolicy set
+	// to Local are advertised only from the nodes with ready local endpoints.
+	// The daemon must run with service advertisement enabled for this to have effect.
+	// +optional
+	ServiceSelector *metav1.LabelSelector `json:"serviceSelector,omitempty"`
+	// AdvertisePodCIDRs adds the PodCIDRs of the node to the prefixes advertised
+	// from this router instance.
+	// +optional
+	AdvertisePodCIDRs bool `json:"advertisePodCIDRs,omitempty"`
+	// ConditionalPrefixes is a list of prefixes advertised from this router instance
+	// only while the associated condition is satisfied on the node.
+	// +optional
+	ConditionalPrefixes []ConditionalPrefixes `json:"conditionalPrefixes,omitempty"`
+}
+
+// ConditionalPrefixes is a list of prefixes associated to a condition.
+type ConditionalPrefixes struct {
+	// Prefixes is the list of prefixes associated to the condition.
+	// When the condition is not satisfied, the prefixes are withdrawn but they can still
+	// be referenced in the neighbors' toAdvertise section.
+	// +kubebuilder:validation:MinItems=1
+	Prefixes []string `json:"prefixes"`
+	// Condition is the condition that must be satisfied on the node for the
+	// prefixes to be advertised.
+	Condition PrefixCondition `json:"condition"`
+}
+
+// PrefixCondition is a condition evaluated on each node. Pod and Probe are
+// mutually exclusive.
+type PrefixCondition struct {
+	// Pod is satisfied when at least one pod matching it is ready on the node.
+	// +optional
+	Pod *PodCondition `json:"pod,omitempty"`
+	// Probe is satisfied when the probe run by the daemon succeeds.
+	// +optional
+	Probe *ProbeCondition `json:"probe,omitempty"`
+}
+
+// PodCondition selects the pods running on the node.
+type PodCondition struct {
+	// Namespace is the namespace of the pods. When not set, the pods
+	// of all the namespaces are considered.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Selector is the label selector of the pods.
+	Selector metav1.LabelSelector `json:"selector"`
+}
+
+// ProbeCondition is a check run periodically by the daemon. HTTPGet and TCPSocket
+// are mutually exclusive.
+type ProbeCondition struct {
+	// HTTPGet performs an HTTP GET request, successful if it returns a 2xx or 3xx code.
+	// +optional
+	HTTPGet *HTTPGetProbe `json:"httpGet,omitempty"`
+	// TCPSocket opens a TCP connection, successful if the connection is established.
+	// +optional
+	TCPSocket *TCPSocketProbe `json:"tcpSocket,omitempty"`
+	// PeriodSeconds is how often the probe is performed.
+	// Defaults to 10 seconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+	// TimeoutSeconds is the timeout of each attempt.
+	// Defaults to 1 second.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// FailureThreshold is the number of consecutive failures after which the
+	// probe is considered failed. Defaults to 3.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+// HTTPGetProbe describes an HTTP GET request.
+type HTTPGetProbe struct {
+	// Host is the host to connect to. Defaults to 127.0.0.1.
+	// +optional
+	Host string `json:"host,omitempty"`
+	// Port is the port to connect to.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+	// Path is the path of the request.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// TCPSocketProbe describes a TCP connection.
+type TCPSocketProbe struct {
+	// Host is the host to connect to. Defaults to 127.0.0.1.
+	// +optional
+	Host string `json:"host,omitempty"`
+	// Port is the port to connect to.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
 }
 
 // Neighbor represents a BGP Neighbor we want FRR to connect to.
@@ -87,8 +233,14 @@ type Neighbor struct {
 	// ASN is the AS number to use for the local end of the session.
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=4294967295
+	// +optional
 	ASN uint32 `json:"asn"`
 
+	// ASNFrom is a node variable expression, such as ${node.labels['tor-asn']}, resolving
+	// to the AS number of the neighbor. ASN and ASNFrom are mutually exclusive, and one of them must be set.
+	// +optional
+	ASNFrom string `json:"asnFrom,omitempty"`
+
 	// Address is the IP address to establish the session with.
 	Address string `json:"address"`
 
@@ -145,6 +297,20 @@ type Neighbor struct {
 	// ToReceive represents the list of prefixes to receive from the given neighbor.
 	// +optional
 	ToReceive Receive `json:"toReceive,omitempty"`
+
+	// Disabled administratively shuts down the session with the neighbor,
+	// keeping its configuration. When the same neighbor is defined in
+	// multiple configurations, it is disabled if any of them disables it.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// DisabledMessage is the message sent to the neighbor with the
+	// shutdown notification when the session is disabled. It must be made
+	// of printable characters only.
+	// +kubebuilder:validation:MaxLength=128
+	// +kubebuilder:validation:Pattern=`^[^\x00-\x1F\x7F]*$`
+	// +optional
+	DisabledMessage string `json:"disabledMessage,omitempty"`
 }
 
 // Advertise represents a list of prefixes to advertise to the given neighbor.
@@ -4,14 +4,18 @@ package v1beta1
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -21,7 +25,9 @@ import (
 var (
 	Logger        log.Logger
 	WebhookClient client.Reader
-	Validate      func(resources ...client.ObjectList) error
+	Validate      func(resources ...client.ObjectList) ([]string, error)
+	// ValidatePolicy checks the given configuration against the cluster policy.
+	ValidatePolicy func(cfg *FRRConfiguration, resources ...client.ObjectList) error
 )
 
 func (frrConfig *FRRConfiguration) SetupWebhookWithManager(mgr ctrl.Manager) error {
@@ -34,10 +40,15 @@ func (frrConfig *FRRConfiguration) SetupWebhookWithManager(mgr ctrl.Manager) err
 
 var _ webhook.Validator = &FRRConfiguration{}
 
+// fieldErrorer is implemented by the validation errors that can be related
+// to a field of a given configuration.
+type fieldErrorer interface {
+	FieldErrorFor(object types.NamespacedName) *field.Error
+}
+
 type nodeAndConfigs struct {
-	name   string
-	labels map[string]string
-	cfgs   *FRRConfigurationList
+	node corev1.Node
+	cfgs *FRRConfigurationList
 }
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for FRRConfiguration.
@@ -45,7 +56,7 @@ func (frrConfig *FRRConfiguration) ValidateCreate() (admission.Warnings, error)
 	level.Debug(Logger).Log("webhook", "frrconfiguration", "action", "create", "name", frrConfig.Name, "namespace", frrConfig.Namespace)
 	defer level.Debug(Logger).Log("webhook", "frrconfiguration", "action", "end create", "name", frrConfig.Name, "namespace", frrConfig.Namespace)
 
-	return nil, validateConfig(frrConfig)
+	return validateConfig(frrConfig)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for FRRConfiguration.
@@ -53,7 +64,7 @@ func (frrConfig *FRRConfiguration) ValidateUpdate(old runtime.Object) (admission
 	level.Debug(Logger).Log("webhook", "frrconfiguration", "action", "update", "name", frrConfig.Name, "namespace", frrConfig.Namespace)
 	defer level.Debug(Logger).Log("webhook", "frrconfiguration", "action", "end update", "name", frrConfig.Name, "namespace", frrConfig.Namespace)
 
-	return nil, validateConfig(frrConfig)
+	return validateConfig(frrConfig)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for FRRConfiguration.
@@ -61,29 +72,38 @@ func (frrConfig *FRRConfiguration) ValidateDelete() (admission.Warnings, error)
 	return nil, nil
 }
 
-func validateConfig(frrConfig *FRRConfiguration) error {
+func validateConfig(frrConfig *FRRConfiguration) (admission.Warnings, error) {
 	selector, err := metav1.LabelSelectorAsSelector(&frrConfig.Spec.NodeSelector)
 	if err != nil {
-		return errors.Wrapf(err, "resource contains an invalid NodeSelector")
+		return nil, errors.Wrapf(err, "resource contains an invalid NodeSelector")
 	}
 
 	existingNodes, err := getNodes()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	existingFRRConfigurations, err := getFRRConfigurations()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	existingDefaults, err := getFRRDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	existingPolicies, err := getFRRPolicies()
+	if err != nil {
+		return nil, err
 	}
 
 	matchingNodes := []nodeAndConfigs{}
 	for _, n := range existingNodes {
 		if selector.Matches(labels.Set(n.Labels)) {
 			matchingNodes = append(matchingNodes, nodeAndConfigs{
-				name:   n.Name,
-				labels: n.Labels,
-				cfgs:   &FRRConfigurationList{},
+				node: n,
+				cfgs: &FRRConfigurationList{},
 			})
 		}
 	}
@@ -104,21 +124,52 @@ func validateConfig(frrConfig *FRRConfiguration) error {
 				continue
 			}
 
-			if selector.Matches(labels.Set(n.labels)) {
+			if selector.Matches(labels.Set(n.node.Labels)) {
 				n.cfgs.Items = append(n.cfgs.Items, *cfg.DeepCopy())
 			}
 		}
 		n.cfgs.Items = append(n.cfgs.Items, *frrConfig.DeepCopy())
 	}
 
+	var warnings admission.Warnings
 	for _, n := range matchingNodes {
-		err := Validate(n.cfgs)
+		err := ValidatePolicy(frrConfig, &corev1.NodeList{Items: []corev1.Node{n.node}}, existingPolicies)
+		if err != nil {
+			return nil, nodeValidationError(frrConfig, n.node.Name, err, "resource is not allowed for node %s")
+		}
+
+		nodeWarnings, err := Validate(n.cfgs, &corev1.NodeList{Items: []corev1.Node{n.node}}, existingDefaults, existingPolicies)
 		if err != nil {
-			return errors.Wrapf(err, "resource is invalid for node %s", n.name)
+			return nil, nodeValidationError(frrConfig, n.node.Name, err, "resource is invalid for node %s")
+		}
+		for _, w := range nodeWarnings {
+			warnings = append(warnings, fmt.Sprintf("node %s: %s", n.node.Name, w))
 		}
 	}
 
-	return nil
+	return warnings, nil
+}
+
+// nodeValidationError returns the error to be returned when the given configuration fails the
+// validation for the given node. Errors related to a field of the configuration are returned as
+// field errors, the others are wrapped with the given message.
+func nodeValidationError(frrConfig *FRRConfiguration, node string, err error, message string) error {
+	fieldErr := fieldErrorFor(frrConfig, err)
+	if fieldErr == nil {
+		return errors.Wrapf(err, message, node)
+	}
+	fieldErr.Detail = fmt.Sprintf("%s (for node %s)", fieldErr.Detail, node)
+	return apierrors.NewInvalid(GroupVersion.WithKind("FRRConfiguration").GroupKind(), frrConfig.Name, field.ErrorList{fieldErr})
+}
+
+// fieldErrorFor returns the given validation error as an error on a field of the given
+// configuration, or nil if the error is not related to any of its fields.
+func fieldErrorFor(frrConfig *FRRConfiguration, err error) *field.Error {
+	var fe fieldErrorer
+	if !errors.As(err, &fe) {
+		return nil
+	}
+	return fe.FieldErrorFor(types.NamespacedName{Namespace: frrConfig.Namespace, Name: frrConfig.Name})
 }
 
 var getFRRConfigurations = func() (*FRRConfigurationList, error) {
@@ -138,3 +189,21 @@ var getNodes = func() ([]corev1.Node, error) {
 	}
 	return nodesList.Items, nil
 }
+
+var getFRRDefaults = func() (*FRRDefaultsList, error) {
+	defaultsList := &FRRDefaultsList{}
+	err := WebhookClient.List(context.Background(), defaultsList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to get existing FRRDefaults objects")
+	}
+	return defaultsList, nil
+}
+
+var getFRRPolicies = func() (*FRRPolicyList, error) {
+	policiesList := &FRRPolicyList{}
+	err := WebhookClient.List(context.Background(), policiesList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to get existing FRRPolicy objects")
+	}
+	return policiesList, nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	v1core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 const TestNamespace = "test-namespace"
@@ -44,9 +45,21 @@ func TestValidateFRRConfiguration(t *testing.T) {
 		}, nil
 	}
 
+	toRestoreDefaults := getFRRDefaults
+	getFRRDefaults = func() (*FRRDefaultsList, error) {
+		return &FRRDefaultsList{}, nil
+	}
+
+	toRestorePolicies := getFRRPolicies
+	getFRRPolicies = func() (*FRRPolicyList, error) {
+		return &FRRPolicyList{}, nil
+	}
+
 	defer func() {
 		getFRRConfigurations = toRestore
 		getNodes = toRestoreNodes
+		getFRRDefaults = toRestoreDefaults
+		getFRRPolicies = toRestorePolicies
 	}()
 
 	tests := []struct {
@@ -145,20 +158,29 @@ func TestValidateFRRConfiguration(t *testing.T) {
 	}
 	for _, test := range tests {
 		var err error
+		var warnings admission.Warnings
 		mock := &mockValidator{}
 		Validate = mock.Validate
+		ValidatePolicy = mock.ValidatePolicy
 		mock.forceError = test.failValidate
+		mock.warnings = []string{"conflict resolved"}
 
 		if test.isNew {
-			_, err = test.config.ValidateCreate()
+			warnings, err = test.config.ValidateCreate()
 		} else {
-			_, err = test.config.ValidateUpdate(nil)
+			warnings, err = test.config.ValidateUpdate(nil)
 		}
 		if test.failValidate && err == nil {
 			t.Fatalf("test %s failed, expecting error", test.desc)
 		}
+		if !test.failValidate && test.expected != nil && !cmp.Equal(warnings, admission.Warnings{"node testnode: conflict resolved"}) {
+			t.Fatalf("test %s failed, unexpected warnings %v", test.desc, warnings)
+		}
 		if !cmp.Equal(test.expected, mock.configs) {
 			t.Fatalf("test %s failed, %s", test.desc, cmp.Diff(test.expected, mock.configs))
 		}
+		if test.expected != nil && (mock.nodes == nil || len(mock.nodes.Items) != 1 || mock.nodes.Items[0].Name != "testnode") {
+			t.Fatalf("test %s failed, expected the validated node to be passed, got %v", test.desc, mock.nodes)
+		}
 	}
 }
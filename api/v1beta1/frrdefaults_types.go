@@ -0,0 +1,97 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FRRDefaultsName is the name of the only FRRDefaults instance taken into account.
+const FRRDefaultsName = "default"
+
+// CIDR is an ip network in cidr notation.
+// +kubebuilder:validation:Format="cidr"
+type CIDR string
+
+// FRRDefaultsSpec defines the cluster wide defaults applied to the configurations.
+type FRRDefaultsSpec struct {
+	// HoldTime is the BGP hold time applied to the neighbors not setting one.
+	// Defaults to 180s.
+	// +optional
+	HoldTime *metav1.Duration `json:"holdTime,omitempty"`
+
+	// KeepaliveTime is the BGP keepalive time applied to the neighbors not setting one.
+	// Defaults to 60s.
+	// +optional
+	KeepaliveTime *metav1.Duration `json:"keepaliveTime,omitempty"`
+
+	// ConnectTime is the BGP connect time applied to the neighbors not setting one.
+	// Defaults to 60s.
+	// +kubebuilder:validation:XValidation:message="connect time should be between 1 seconds to 65535",rule="duration(self).getSeconds() >= 1 && duration(self).getSeconds() <= 65535"
+	// +kubebuilder:validation:XValidation:message="connect time should contain a whole number of seconds",rule="duration(self).getMilliseconds() % 1000 == 0"
+	// +optional
+	ConnectTime *metav1.Duration `json:"connectTime,omitempty"`
+
+	// Port is the port to dial when establishing the sessions with the neighbors not setting one.
+	// Defaults to 179.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=16384
+	Port *uint16 `json:"port,omitempty"`
+
+	// BFDProfile is the BFD profile used by the neighbors not referencing any profile.
+	// If not set, no BFD session is set up for those neighbors.
+	// +optional
+	BFDProfile *BFDProfile `json:"bfdProfile,omitempty"`
+
+	// AlwaysBlock is a list of cidrs that are never accepted from any neighbor,
+	// in addition to the ones passed to the daemon via the always-block parameter.
+	// +optional
+	AlwaysBlock []CIDR `json:"alwaysBlock,omitempty"`
+
+	// LogLevel is the log level of the FRR daemons, overriding the one derived
+	// from the log level of the frr-k8s daemon.
+	// +optional
+	// +kubebuilder:validation:Enum=all;debug;info;warn;error;none
+	LogLevel string `json:"logLevel,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:validation:XValidation:rule="self.metadata.name == 'default'",message="the FRRDefaults resource must be named default"
+
+// FRRDefaults is the Schema for the cluster wide defaults applied to the FRRConfigurations.
+// Only the instance named "default" is taken into account.
+type FRRDefaults struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FRRDefaultsSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FRRDefaultsList contains a list of FRRDefaults.
+type FRRDefaultsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FRRDefaults `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FRRDefaults{}, &FRRDefaultsList{})
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FRRPolicyName is the name of the only FRRPolicy instance taken into account.
+const FRRPolicyName = "default"
+
+// FRRPolicySpec defines the cluster wide policies restricting what the FRRConfigurations are allowed to do.
+type FRRPolicySpec struct {
+	// RawConfig restricts the usage of the raw configuration in the FRRConfigurations.
+	// If not set, the raw configuration is allowed in any configuration.
+	// +optional
+	RawConfig *RawConfigPolicy `json:"rawConfig,omitempty"`
+
+	// Tenants binds namespaces to the resources the FRRConfigurations in them are allowed to use.
+	// The configurations in the namespaces not bound to any tenant are not restricted.
+	// +optional
+	Tenants []TenantPolicy `json:"tenants,omitempty"`
+}
+
+// TenantPolicy restricts the resources the FRRConfigurations in a set of namespaces are allowed to use.
+// When a namespace is bound to multiple tenants, their allowances are combined.
+type TenantPolicy struct {
+	// Namespaces is the list of namespaces bound to the tenant.
+	// +kubebuilder:validation:MinItems=1
+	Namespaces []string `json:"namespaces"`
+
+	// VRFs is the list of vrfs the routers are allowed to use, where the default vrf is
+	// referred to as "default". If no tenant bound to the namespace sets it, any vrf is allowed.
+	// +optional
+	VRFs []string `json:"vrfs,omitempty"`
+
+	// NeighborCIDRs is the list of cidrs the addresses of the neighbors must belong to.
+	// If no tenant bound to the namespace sets it, any neighbor is allowed.
+	// +optional
+	NeighborCIDRs []CIDR `json:"neighborCIDRs,omitempty"`
+
+	// Prefixes is the list of cidrs the prefixes advertised by the routers must be contained in.
+	// If no tenant bound to the namespace sets it, any prefix is allowed.
+	// When set, the routers can't advertise the services nor the pod cidrs, as their
+	// prefixes are not known in advance.
+	// +optional
+	Prefixes []CIDR `json:"prefixes,omitempty"`
+}
+
+// RawConfigPolicy restricts the usage of the raw configuration.
+type RawConfigPolicy struct {
+	// Disabled rejects all the configurations containing raw configuration.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// AllowedNamespaces is the list of namespaces the configurations containing raw
+	// configuration are allowed in. If empty, the raw configuration is allowed in any namespace.
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
+	// AllowedCommands is the list of command prefixes the raw configuration is allowed to use.
+	// Each line of the raw configuration, ignoring the leading spaces, the empty lines and the
+	// comments, must start with one of them. If empty, any command is allowed.
+	// +optional
+	AllowedCommands []string `json:"allowedCommands,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:validation:XValidation:rule="self.metadata.name == 'default'",message="the FRRPolicy resource must be named default"
+
+// FRRPolicy is the Schema for the cluster wide policies restricting the FRRConfigurations.
+// Only the instance named "default" is taken into account.
+// As it restricts what the FRRConfigurations are allowed to do, only the cluster
+// administrators are expected to be allowed to edit it.
+type FRRPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FRRPolicySpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FRRPolicyList contains a list of FRRPolicy.
+type FRRPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FRRPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FRRPolicy{}, &FRRPolicyList{})
+}
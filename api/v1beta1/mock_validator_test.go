@@ -11,23 +11,34 @@ import (
 type mockValidator struct {
 	configs    *FRRConfigurationList
 	nodes      *v1.NodeList
+	defaults   *FRRDefaultsList
+	policies   *FRRPolicyList
 	forceError bool
+	warnings   []string
 }
 
-func (m *mockValidator) Validate(objects ...client.ObjectList) error {
+func (m *mockValidator) Validate(objects ...client.ObjectList) ([]string, error) {
 	for _, obj := range objects { // assuming one object per type
 		switch list := obj.(type) {
 		case *FRRConfigurationList:
 			m.configs = list
 		case *v1.NodeList:
 			m.nodes = list
+		case *FRRDefaultsList:
+			m.defaults = list
+		case *FRRPolicyList:
+			m.policies = list
 		default:
 			panic("unexpected type")
 		}
 	}
 
 	if m.forceError {
-		return errors.New("error!")
+		return nil, errors.New("error!")
 	}
+	return m.warnings, nil
+}
+
+func (m *mockValidator) ValidatePolicy(cfg *FRRConfiguration, objects ...client.ObjectList) error {
 	return nil
 }
@@ -195,6 +195,27 @@ func (in *CommunityPrefixes) DeepCopy() *CommunityPrefixes {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConditionalPrefixes) DeepCopyInto(out *ConditionalPrefixes) {
+	*out = *in
+	if in.Prefixes != nil {
+		in, out := &in.Prefixes, &out.Prefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Condition.DeepCopyInto(&out.Condition)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConditionalPrefixes.
+func (in *ConditionalPrefixes) DeepCopy() *ConditionalPrefixes {
+	if in == nil {
+		return nil
+	}
+	out := new(ConditionalPrefixes)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FRRConfiguration) DeepCopyInto(out *FRRConfiguration) {
 	*out = *in
@@ -258,7 +279,7 @@ func (in *FRRConfigurationList) DeepCopyObject() runtime.Object {
 func (in *FRRConfigurationSpec) DeepCopyInto(out *FRRConfigurationSpec) {
 	*out = *in
 	in.BGP.DeepCopyInto(&out.BGP)
-	out.Raw = in.Raw
+	in.Raw.DeepCopyInto(&out.Raw)
 	in.NodeSelector.DeepCopyInto(&out.NodeSelector)
 }
 
@@ -287,13 +308,116 @@ func (in *FRRConfigurationStatus) DeepCopy() *FRRConfigurationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FRRDefaults) DeepCopyInto(out *FRRDefaults) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FRRDefaults.
+func (in *FRRDefaults) DeepCopy() *FRRDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(FRRDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FRRDefaults) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FRRDefaultsList) DeepCopyInto(out *FRRDefaultsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FRRDefaults, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FRRDefaultsList.
+func (in *FRRDefaultsList) DeepCopy() *FRRDefaultsList {
+	if in == nil {
+		return nil
+	}
+	out := new(FRRDefaultsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FRRDefaultsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FRRDefaultsSpec) DeepCopyInto(out *FRRDefaultsSpec) {
+	*out = *in
+	if in.HoldTime != nil {
+		in, out := &in.HoldTime, &out.HoldTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.KeepaliveTime != nil {
+		in, out := &in.KeepaliveTime, &out.KeepaliveTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ConnectTime != nil {
+		in, out := &in.ConnectTime, &out.ConnectTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(uint16)
+		**out = **in
+	}
+	if in.BFDProfile != nil {
+		in, out := &in.BFDProfile, &out.BFDProfile
+		*out = new(BFDProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlwaysBlock != nil {
+		in, out := &in.AlwaysBlock, &out.AlwaysBlock
+		*out = make([]CIDR, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FRRDefaultsSpec.
+func (in *FRRDefaultsSpec) DeepCopy() *FRRDefaultsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FRRDefaultsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FRRNodeState) DeepCopyInto(out *FRRNodeState) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FRRNodeState.
@@ -364,6 +488,35 @@ func (in *FRRNodeStateSpec) DeepCopy() *FRRNodeStateSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FRRNodeStateStatus) DeepCopyInto(out *FRRNodeStateStatus) {
 	*out = *in
+	if in.ConfigDrift != nil {
+		in, out := &in.ConfigDrift, &out.ConfigDrift
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReloadHistory != nil {
+		in, out := &in.ReloadHistory, &out.ReloadHistory
+		*out = make([]ReloadAttempt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DisabledNeighbors != nil {
+		in, out := &in.DisabledNeighbors, &out.DisabledNeighbors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IgnoredConfigurations != nil {
+		in, out := &in.IgnoredConfigurations, &out.IgnoredConfigurations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FRRNodeStateStatus.
@@ -376,6 +529,106 @@ func (in *FRRNodeStateStatus) DeepCopy() *FRRNodeStateStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FRRPolicy) DeepCopyInto(out *FRRPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FRRPolicy.
+func (in *FRRPolicy) DeepCopy() *FRRPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(FRRPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FRRPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FRRPolicyList) DeepCopyInto(out *FRRPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FRRPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FRRPolicyList.
+func (in *FRRPolicyList) DeepCopy() *FRRPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(FRRPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FRRPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FRRPolicySpec) DeepCopyInto(out *FRRPolicySpec) {
+	*out = *in
+	if in.RawConfig != nil {
+		in, out := &in.RawConfig, &out.RawConfig
+		*out = new(RawConfigPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]TenantPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FRRPolicySpec.
+func (in *FRRPolicySpec) DeepCopy() *FRRPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FRRPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPGetProbe) DeepCopyInto(out *HTTPGetProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPGetProbe.
+func (in *HTTPGetProbe) DeepCopy() *HTTPGetProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPGetProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalPrefPrefixes) DeepCopyInto(out *LocalPrefPrefixes) {
 	*out = *in
@@ -434,6 +687,47 @@ func (in *Neighbor) DeepCopy() *Neighbor {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodCondition) DeepCopyInto(out *PodCondition) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodCondition.
+func (in *PodCondition) DeepCopy() *PodCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(PodCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrefixCondition) DeepCopyInto(out *PrefixCondition) {
+	*out = *in
+	if in.Pod != nil {
+		in, out := &in.Pod, &out.Pod
+		*out = new(PodCondition)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Probe != nil {
+		in, out := &in.Probe, &out.Probe
+		*out = new(ProbeCondition)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefixCondition.
+func (in *PrefixCondition) DeepCopy() *PrefixCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(PrefixCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PrefixSelector) DeepCopyInto(out *PrefixSelector) {
 	*out = *in
@@ -449,9 +743,39 @@ func (in *PrefixSelector) DeepCopy() *PrefixSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeCondition) DeepCopyInto(out *ProbeCondition) {
+	*out = *in
+	if in.HTTPGet != nil {
+		in, out := &in.HTTPGet, &out.HTTPGet
+		*out = new(HTTPGetProbe)
+		**out = **in
+	}
+	if in.TCPSocket != nil {
+		in, out := &in.TCPSocket, &out.TCPSocket
+		*out = new(TCPSocketProbe)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeCondition.
+func (in *ProbeCondition) DeepCopy() *ProbeCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RawConfig) DeepCopyInto(out *RawConfig) {
 	*out = *in
+	if in.Scoped != nil {
+		in, out := &in.Scoped, &out.Scoped
+		*out = make([]ScopedRawConfig, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RawConfig.
@@ -464,6 +788,31 @@ func (in *RawConfig) DeepCopy() *RawConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RawConfigPolicy) DeepCopyInto(out *RawConfigPolicy) {
+	*out = *in
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedCommands != nil {
+		in, out := &in.AllowedCommands, &out.AllowedCommands
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RawConfigPolicy.
+func (in *RawConfigPolicy) DeepCopy() *RawConfigPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RawConfigPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Receive) DeepCopyInto(out *Receive) {
 	*out = *in
@@ -480,6 +829,23 @@ func (in *Receive) DeepCopy() *Receive {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReloadAttempt) DeepCopyInto(out *ReloadAttempt) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReloadAttempt.
+func (in *ReloadAttempt) DeepCopy() *ReloadAttempt {
+	if in == nil {
+		return nil
+	}
+	out := new(ReloadAttempt)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Router) DeepCopyInto(out *Router) {
 	*out = *in
@@ -495,6 +861,18 @@ func (in *Router) DeepCopyInto(out *Router) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ServiceSelector != nil {
+		in, out := &in.ServiceSelector, &out.ServiceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConditionalPrefixes != nil {
+		in, out := &in.ConditionalPrefixes, &out.ConditionalPrefixes
+		*out = make([]ConditionalPrefixes, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Router.
@@ -506,3 +884,68 @@ func (in *Router) DeepCopy() *Router {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScopedRawConfig) DeepCopyInto(out *ScopedRawConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScopedRawConfig.
+func (in *ScopedRawConfig) DeepCopy() *ScopedRawConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ScopedRawConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPSocketProbe) DeepCopyInto(out *TCPSocketProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPSocketProbe.
+func (in *TCPSocketProbe) DeepCopy() *TCPSocketProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPSocketProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantPolicy) DeepCopyInto(out *TenantPolicy) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VRFs != nil {
+		in, out := &in.VRFs, &out.VRFs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NeighborCIDRs != nil {
+		in, out := &in.NeighborCIDRs, &out.NeighborCIDRs
+		*out = make([]CIDR, len(*in))
+		copy(*out, *in)
+	}
+	if in.Prefixes != nil {
+		in, out := &in.Prefixes, &out.Prefixes
+		*out = make([]CIDR, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantPolicy.
+func (in *TenantPolicy) DeepCopy() *TenantPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
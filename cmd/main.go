@@ -22,6 +22,7 @@ import (
 	"net"
 	"os"
 	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -48,6 +49,7 @@ import (
 	frrk8sv1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
 	"github.com/metallb/frr-k8s/internal/controller"
 	"github.com/metallb/frr-k8s/internal/frr"
+	"github.com/metallb/frr-k8s/internal/health"
 	"github.com/metallb/frr-k8s/internal/logging"
 	"github.com/metallb/frr-k8s/internal/version"
 	"github.com/open-policy-agent/cert-controller/pkg/rotator"
@@ -67,6 +69,10 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		os.Exit(render(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	var (
 		metricsAddr                   string
 		probeAddr                     string
@@ -80,6 +86,17 @@ func main() {
 		webhookMode                   string
 		pprofAddr                     string
 		alwaysBlockCIDRs              string
+		advertiseServices             bool
+		drainModeFlag                 string
+		validateRawConfig             bool
+		driftModeFlag                 string
+		driftCheckInterval            time.Duration
+		reloadDebounce                time.Duration
+		reloadRetryInterval           time.Duration
+		reloadMaxRetryInterval        time.Duration
+		persistedConfigFile           string
+		shutdownModeFlag              string
+		shutdownDrainPeriod           time.Duration
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "127.0.0.1:7572", "The address the metric endpoint binds to.")
@@ -94,6 +111,18 @@ func main() {
 	flag.StringVar(&certServiceName, "cert-service-name", "frr-k8s-webhook-service", "The service name used to generate the TLS cert's hostname")
 	flag.StringVar(&pprofAddr, "pprof-bind-address", "", "The address the pprof endpoints bind to.")
 	flag.StringVar(&alwaysBlockCIDRs, "always-block", "", "a list of comma separated cidrs we need to always block")
+	flag.BoolVar(&advertiseServices, "advertise-services", false, "watch services and endpointslices to advertise the IPs of the services selected by the routers")
+	flag.StringVar(&drainModeFlag, "drain-mode", "", fmt.Sprintf("how the advertised routes are handled when the node is cordoned or annotated with %s=true. must be one of: [%s, %s, %s] or empty to disable it",
+		controller.DrainAnnotation, frr.DrainGracefulShutdown, frr.DrainASPathPrepend, frr.DrainWithdraw))
+	flag.BoolVar(&validateRawConfig, "validate-raw-config", false, "in webhook mode, check the raw configs using FRR's parser. requires vtysh to be available")
+	flag.StringVar(&driftModeFlag, "drift-mode", string(frr.DriftReport), fmt.Sprintf("what to do when the running FRR config drifts from the rendered one. must be one of: [%s, %s]", frr.DriftReport, frr.DriftEnforce))
+	flag.DurationVar(&driftCheckInterval, "drift-check-interval", time.Minute, "the interval the running FRR config is compared with the rendered one at")
+	flag.DurationVar(&reloadDebounce, "reload-debounce", 3*time.Second, "the time the changes to the FRR config are coalesced for before reloading it")
+	flag.DurationVar(&reloadRetryInterval, "reload-retry-interval", 5*time.Second, "the interval a failed FRR reload is first retried after, doubled at each consecutive failure")
+	flag.DurationVar(&reloadMaxRetryInterval, "reload-max-retry-interval", 5*time.Minute, "the maximum interval a failed FRR reload is retried after")
+	flag.StringVar(&persistedConfigFile, "persisted-config-file", "", "the file the last applied FRR config is stored in and applied from at startup, before the API server is reachable. leave empty to disable it")
+	flag.StringVar(&shutdownModeFlag, "shutdown-mode", "", fmt.Sprintf("how the advertised routes are handled when the daemon shuts down. must be one of: [%s, %s] or empty to disable it", frr.ShutdownGraceful, frr.ShutdownWithdraw))
+	flag.DurationVar(&shutdownDrainPeriod, "shutdown-drain-period", 30*time.Second, "the time the peers are given to converge after the routes are drained on shutdown")
 
 	opts := zap.Options{
 		Development: true,
@@ -117,6 +146,9 @@ func main() {
 		Cache: cache.Options{
 			ByObject: map[client.Object]cache.ByObject{
 				&corev1.Secret{}: namespaceSelector,
+				&corev1.Pod{}: {
+					Field: fields.OneTermEqualSelector("spec.nodeName", nodeName),
+				},
 			},
 		},
 		WebhookServer: webhook.NewServer(
@@ -148,6 +180,11 @@ func main() {
 	}
 
 	enableWebhook := webhookMode == "onlywebhook"
+	// frrStopped is closed once the routes are drained and the daemon can exit.
+	frrStopped := make(chan struct{})
+	if enableWebhook {
+		close(frrStopped)
+	}
 	startListeners := make(chan struct{})
 	if enableWebhook && !disableCertRotation {
 		setupLog.Info("Starting certs generator")
@@ -165,7 +202,7 @@ func main() {
 
 		if enableWebhook {
 			setupLog.Info("Starting webhooks")
-			err := setupWebhook(mgr, logger)
+			err := setupWebhook(mgr, logger, validateRawConfig)
 			if err != nil {
 				setupLog.Error(err, "unable to create", "webhooks")
 				os.Exit(1)
@@ -178,7 +215,30 @@ func main() {
 		reloadStatus := func() {
 			reloadStatusChan <- controller.NewStateEvent()
 		}
-		frrInstance := frr.NewFRR(ctx, reloadStatus, logger, logging.Level(logLevel))
+		driftMode, err := frr.ParseDriftMode(driftModeFlag)
+		if err != nil {
+			setupLog.Error(err, "failed to parse the drift-mode parameter", "drift-mode", driftModeFlag)
+			os.Exit(1)
+		}
+		shutdownMode, err := frr.ParseShutdownMode(shutdownModeFlag)
+		if err != nil {
+			setupLog.Error(err, "failed to parse the shutdown-mode parameter", "shutdown-mode", shutdownModeFlag)
+			os.Exit(1)
+		}
+		frrInstance := frr.NewFRR(ctx, reloadStatus, logger, logging.Level(logLevel), frr.Options{
+			DriftMode:           driftMode,
+			DriftCheckInterval:  driftCheckInterval,
+			DebounceInterval:    reloadDebounce,
+			RetryInterval:       reloadRetryInterval,
+			MaxRetryInterval:    reloadMaxRetryInterval,
+			PersistedConfigFile: persistedConfigFile,
+			ShutdownMode:        shutdownMode,
+			ShutdownDrainPeriod: shutdownDrainPeriod,
+		})
+		go func() {
+			<-frrInstance.ShutdownDone()
+			close(frrStopped)
+		}()
 
 		alwaysBlock, err := parseCIDRs(alwaysBlockCIDRs)
 		if err != nil {
@@ -186,14 +246,34 @@ func main() {
 			os.Exit(1)
 		}
 
+		drainMode, err := frr.ParseDrainMode(drainModeFlag)
+		if err != nil {
+			setupLog.Error(err, "failed to parse the drain-mode parameter", "drain-mode", drainModeFlag)
+			os.Exit(1)
+		}
+
+		// The channel is buffered and the notifications are dropped when one is already
+		// pending, so the prober never blocks once the controller stops reading.
+		healthUpdateChan := make(chan event.GenericEvent, 1)
+		prober := health.NewProber(ctx, func() {
+			select {
+			case healthUpdateChan <- controller.NewHealthEvent():
+			default:
+			}
+		}, logger)
+
 		configReconciler := &controller.FRRConfigurationReconciler{
-			Client:           mgr.GetClient(),
-			Scheme:           mgr.GetScheme(),
-			FRRHandler:       frrInstance,
-			Logger:           logger,
-			NodeName:         nodeName,
-			ReloadStatus:     reloadStatus,
-			AlwaysBlockCIDRS: alwaysBlock,
+			Client:            mgr.GetClient(),
+			Scheme:            mgr.GetScheme(),
+			FRRHandler:        frrInstance,
+			Logger:            logger,
+			NodeName:          nodeName,
+			ReloadStatus:      reloadStatus,
+			AlwaysBlockCIDRS:  alwaysBlock,
+			AdvertiseServices: advertiseServices,
+			Prober:            prober,
+			HealthUpdate:      healthUpdateChan,
+			DrainMode:         drainMode,
 		}
 		if err = configReconciler.SetupWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "FRRConfiguration")
@@ -219,6 +299,7 @@ func main() {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+	<-frrStopped
 }
 
 const (
@@ -262,12 +343,16 @@ func setupCertRotation(notifyFinished chan struct{}, mgr manager.Manager, logger
 	return nil
 }
 
-func setupWebhook(mgr manager.Manager, logger log.Logger) error {
-	level.Info(logger).Log("op", "startup", "action", "webhooks enabled")
+func setupWebhook(mgr manager.Manager, logger log.Logger, validateRawConfig bool) error {
+	level.Info(logger).Log("op", "startup", "action", "webhooks enabled", "validate-raw-config", validateRawConfig)
 
 	frrk8sv1beta1.Logger = logger
 	frrk8sv1beta1.WebhookClient = mgr.GetAPIReader()
 	frrk8sv1beta1.Validate = controller.Validate
+	frrk8sv1beta1.ValidatePolicy = controller.ValidatePolicy
+	if validateRawConfig {
+		frrk8sv1beta1.Validate = controller.ValidateWithRawConfigCheck(frr.CheckConfig)
+	}
 
 	if err := (&frrk8sv1beta1.FRRConfiguration{}).SetupWebhookWithManager(mgr); err != nil {
 		level.Error(logger).Log("op", "startup", "error", err, "msg", "unable to create webhook", "webhook", "FRRConfigurations")
@@ -0,0 +1,258 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	frrk8sv1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
+	"github.com/metallb/frr-k8s/internal/controller"
+	"github.com/metallb/frr-k8s/internal/frr"
+	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const renderUsage = `Usage: frr-k8s render [flags] FILE|DIR...
+
+Renders the FRR configuration of a node out of the FRRConfigurations, Nodes, Secrets,
+FRRDefaults, FRRPolicies, Services, EndpointSlices and Pods contained in the given yaml files, without
+a cluster. The configuration is
+printed to the standard output. If the conversion fails, a json describing the error
+is printed to the standard error instead.
+
+Flags:
+`
+
+// renderError is the structured error printed when the configuration can't be rendered.
+type renderError struct {
+	Reason  controller.ErrorReason `json:"reason"`
+	Object  string                 `json:"object,omitempty"`
+	Field   string                 `json:"field,omitempty"`
+	Message string                 `json:"message"`
+}
+
+// render runs the render command with the given arguments, returning the exit code.
+func render(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, renderUsage)
+		flags.PrintDefaults()
+	}
+	var (
+		nodeName         string
+		nodeLabels       string
+		namespace        string
+		alwaysBlockCIDRs string
+		drainModeFlag    string
+	)
+	flags.StringVar(&nodeName, "node-name", "", "The node to render the configuration for. Required when the files contain more than one node.")
+	flags.StringVar(&nodeLabels, "node-labels", "", "The labels of the node, as a comma separated list of key=value pairs, when the files don't contain the node.")
+	flags.StringVar(&namespace, "namespace", "", "The namespace frr-k8s is deployed in. If set, only the secrets of this namespace are used.")
+	flags.StringVar(&alwaysBlockCIDRs, "always-block", "", "a list of comma separated cidrs we need to always block")
+	flags.StringVar(&drainModeFlag, "drain-mode", "", fmt.Sprintf("how the advertised routes are handled if the node is being drained. must be one of: [%s, %s, %s] or empty to disable it",
+		frr.DrainGracefulShutdown, frr.DrainASPathPrepend, frr.DrainWithdraw))
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	resources, err := readRenderResources(flags.Args(), nodeName, nodeLabels, namespace)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to read the resources: %v\n", err)
+		return 1
+	}
+	resources.AlwaysBlock, err = parseCIDRs(alwaysBlockCIDRs)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to parse the always-block parameter: %v\n", err)
+		return 1
+	}
+	resources.DrainMode, err = frr.ParseDrainMode(drainModeFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to parse the drain-mode parameter: %v\n", err)
+		return 1
+	}
+
+	res, err := controller.Render(resources)
+	if err != nil {
+		writeRenderError(stderr, err)
+		return 1
+	}
+	for _, w := range res.Warnings {
+		fmt.Fprintf(stderr, "warning: conflict resolved by priority: %s\n", w)
+	}
+	for _, ignored := range res.IgnoredConfigurations {
+		fmt.Fprintf(stderr, "warning: configuration not allowed by the cluster policy: %s\n", ignored)
+	}
+	fmt.Fprint(stdout, res.Config)
+	return 0
+}
+
+func writeRenderError(w io.Writer, err error) {
+	res := renderError{
+		Reason:  controller.ReasonFor(err),
+		Message: err.Error(),
+	}
+	var convErr *controller.ConversionError
+	if errors.As(err, &convErr) {
+		if convErr.Object.Name != "" {
+			res.Object = convErr.Object.String()
+		}
+		if convErr.Field != nil {
+			res.Field = convErr.Field.String()
+		}
+		res.Message = convErr.Err.Error()
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if encodeErr := encoder.Encode(res); encodeErr != nil {
+		fmt.Fprintf(w, "%v\n", err)
+	}
+}
+
+// readRenderResources reads the resources contained in the given files, or in the yaml
+// files of the given directories, and picks the node to render the configuration for.
+func readRenderResources(paths []string, nodeName, nodeLabels, namespace string) (controller.RenderResources, error) {
+	res := controller.RenderResources{}
+	nodes := []corev1.Node{}
+
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	for _, file := range renderFiles(paths) {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return res, err
+		}
+		reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+		for {
+			doc, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return res, fmt.Errorf("failed to read %s: %w", file, err)
+			}
+			if len(bytes.TrimSpace(doc)) == 0 {
+				continue
+			}
+			obj, _, err := decoder.Decode(doc, nil, nil)
+			if err != nil {
+				return res, fmt.Errorf("failed to decode %s: %w", file, err)
+			}
+			switch o := obj.(type) {
+			case *frrk8sv1beta1.FRRConfiguration:
+				res.FRRConfigs = append(res.FRRConfigs, *o)
+			case *frrk8sv1beta1.FRRDefaults:
+				res.Defaults = append(res.Defaults, *o)
+			case *frrk8sv1beta1.FRRPolicy:
+				res.Policies = append(res.Policies, *o)
+			case *corev1.Secret:
+				if namespace != "" && o.Namespace != namespace {
+					continue
+				}
+				// The api server merges stringData into data when the secret is created.
+				for k, v := range o.StringData {
+					if o.Data == nil {
+						o.Data = map[string][]byte{}
+					}
+					o.Data[k] = []byte(v)
+				}
+				res.Secrets = append(res.Secrets, *o)
+			case *corev1.Node:
+				nodes = append(nodes, *o)
+			case *corev1.Service:
+				res.Services = append(res.Services, *o)
+			case *discovery.EndpointSlice:
+				res.EndpointSlices = append(res.EndpointSlices, *o)
+			case *corev1.Pod:
+				res.Pods = append(res.Pods, *o)
+			}
+		}
+	}
+
+	node, err := renderNode(nodes, nodeName, nodeLabels)
+	if err != nil {
+		return res, err
+	}
+	res.Node = node
+	return res, nil
+}
+
+// renderFiles returns the given files, replacing the directories with
+// the yaml files they contain.
+func renderFiles(paths []string) []string {
+	res := []string{}
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil || !info.IsDir() {
+			// Missing files are reported when reading them.
+			res = append(res, p)
+			continue
+		}
+		for _, pattern := range []string{"*.yaml", "*.yml"} {
+			matches, _ := filepath.Glob(filepath.Join(p, pattern))
+			res = append(res, matches...)
+		}
+	}
+	return res
+}
+
+// renderNode returns the node to render the configuration for, either picked among the
+// given ones or built out of the given name and labels.
+func renderNode(nodes []corev1.Node, nodeName, nodeLabels string) (*corev1.Node, error) {
+	if nodeLabels != "" {
+		if len(nodes) > 0 {
+			return nil, fmt.Errorf("node-labels can't be used when the files contain nodes")
+		}
+		l, err := labels.ConvertSelectorToLabelsMap(nodeLabels)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the node-labels parameter: %w", err)
+		}
+		res := &corev1.Node{}
+		res.Name = nodeName
+		res.Labels = l
+		return res, nil
+	}
+
+	if nodeName == "" {
+		switch len(nodes) {
+		case 0:
+			return nil, fmt.Errorf("no node in the files, either add one or use node-labels")
+		case 1:
+			return &nodes[0], nil
+		}
+		names := []string{}
+		for _, n := range nodes {
+			names = append(names, n.Name)
+		}
+		return nil, fmt.Errorf("the files contain more than one node [%s], use node-name to pick one", strings.Join(names, ", "))
+	}
+	for i := range nodes {
+		if nodes[i].Name == nodeName {
+			return &nodes[i], nil
+		}
+	}
+	if len(nodes) > 0 {
+		return nil, fmt.Errorf("node %s not found in the files", nodeName)
+	}
+	res := &corev1.Node{}
+	res.Name = nodeName
+	return res, nil
+}
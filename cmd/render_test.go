@@ -0,0 +1,159 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const renderTestConfig = `apiVersion: frrk8s.metallb.io/v1beta1
+kind: FRRConfiguration
+metadata:
+  name: test
+  namespace: frr-k8s-system
+spec:
+  nodeSelector:
+    matchLabels:
+      rack: a
+  bgp:
+    routers:
+    - asn: 64512
+      neighbors:
+      - address: 172.30.0.3
+        asn: 64513
+        passwordSecret:
+          name: bgp-password
+`
+
+const renderTestSecret = `apiVersion: v1
+kind: Secret
+metadata:
+  name: bgp-password
+  namespace: frr-k8s-system
+type: kubernetes.io/basic-auth
+stringData:
+  password: secret
+`
+
+const renderTestNode = `apiVersion: v1
+kind: Node
+metadata:
+  name: node1
+  labels:
+    rack: a
+`
+
+const renderTestService = `apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+  namespace: default
+spec:
+  type: LoadBalancer
+status:
+  loadBalancer:
+    ingress:
+    - ip: 192.0.2.100
+`
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		args          []string
+		expectedLines []string
+		expectedError *renderError
+	}{
+		{
+			name: "node in the files",
+			files: map[string]string{
+				"config.yaml": renderTestConfig + "---\n" + renderTestSecret,
+				"node.yaml":   renderTestNode,
+			},
+			expectedLines: []string{
+				"hostname node1",
+				"router bgp 64512",
+				"neighbor 172.30.0.3 password secret",
+			},
+		},
+		{
+			name: "node labels",
+			files: map[string]string{
+				"config.yaml": renderTestConfig + "---\n" + renderTestSecret,
+			},
+			args: []string{"--node-name=node2", "--node-labels=rack=a"},
+			expectedLines: []string{
+				"hostname node2",
+				"router bgp 64512",
+			},
+		},
+		{
+			name: "services in the files",
+			files: map[string]string{
+				"config.yaml":  strings.Replace(renderTestConfig, "    - asn: 64512\n", "    - asn: 64512\n      serviceSelector: {}\n", 1) + "---\n" + renderTestSecret,
+				"node.yaml":    renderTestNode,
+				"service.yaml": renderTestService,
+			},
+			expectedLines: []string{
+				"router bgp 64512",
+				"network 192.0.2.100/32",
+			},
+		},
+		{
+			name: "secret of another namespace",
+			files: map[string]string{
+				"config.yaml": renderTestConfig + "---\n" + renderTestSecret,
+				"node.yaml":   renderTestNode,
+			},
+			args: []string{"--namespace=metallb-system"},
+			expectedError: &renderError{
+				Reason:  "NotFound",
+				Object:  "frr-k8s-system/test",
+				Field:   "spec.bgp.routers[0].neighbors[0].passwordSecret",
+				Message: "failed to process neighbor 64513@172.30.0.3 for router 64512-: secret bgp-password not found for neighbor 64513@172.30.0.3",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range test.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", name, err)
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+			code := render(append(test.args, dir), &stdout, &stderr)
+			if test.expectedError != nil {
+				if code != 1 {
+					t.Fatalf("expecting exit code 1, got %d", code)
+				}
+				res := &renderError{}
+				if err := json.Unmarshal(stderr.Bytes(), res); err != nil {
+					t.Fatalf("failed to unmarshal the error %q: %v", stderr.String(), err)
+				}
+				if !cmp.Equal(test.expectedError, res) {
+					t.Fatalf("unexpected error (-want +got):\n%s", cmp.Diff(test.expectedError, res))
+				}
+				return
+			}
+			if code != 0 {
+				t.Fatalf("expecting exit code 0, got %d: %s", code, stderr.String())
+			}
+			for _, l := range test.expectedLines {
+				if !strings.Contains(stdout.String(), l) {
+					t.Fatalf("expecting %q in the rendered config:\n%s", l, stdout.String())
+				}
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ package collector
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
@@ -15,11 +16,13 @@ import (
 
 var labels = []string{"peer", "vrf"}
 
+var sessionUpLabels = []string{"peer", "vrf", "admin_shutdown"}
+
 var (
 	sessionUpDesc = prometheus.NewDesc(
 		prometheus.BuildFQName(Namespace, Subsystem, SessionUp.Name),
 		SessionUp.Help,
-		labels,
+		sessionUpLabels,
 		nil,
 	)
 
@@ -158,7 +161,7 @@ func updateNeighborsMetrics(ch chan<- prometheus.Metric, neighbors map[string][]
 			}
 			peerLabel := fmt.Sprintf("%s:%d", n.IP.String(), n.Port)
 
-			ch <- prometheus.MustNewConstMetric(sessionUpDesc, prometheus.GaugeValue, float64(sessionUp), peerLabel, vrf)
+			ch <- prometheus.MustNewConstMetric(sessionUpDesc, prometheus.GaugeValue, float64(sessionUp), peerLabel, vrf, strconv.FormatBool(n.AdminShutdown))
 			ch <- prometheus.MustNewConstMetric(prefixesDesc, prometheus.GaugeValue, float64(n.PrefixSent), peerLabel, vrf)
 			ch <- prometheus.MustNewConstMetric(receivedPrefixesDesc, prometheus.GaugeValue, float64(n.PrefixReceived), peerLabel, vrf)
 			ch <- prometheus.MustNewConstMetric(opensSentDesc, prometheus.CounterValue, float64(n.MsgStats.OpensSent), peerLabel, vrf)
@@ -39,7 +39,7 @@ var (
 	frrk8s_bgp_route_refresh_sent{peer="{{ .NeighborIP }}", vrf="{{ .NeighborVRF }}"} {{ .RouteRefreshSent }}
 	# HELP frrk8s_bgp_session_up BGP session state (1 is up, 0 is down)
 	# TYPE frrk8s_bgp_session_up gauge
-	frrk8s_bgp_session_up{peer="{{ .NeighborIP }}", vrf="{{ .NeighborVRF }}"} {{ .SessionUp }}
+	frrk8s_bgp_session_up{admin_shutdown="{{ .AdminShutdown }}", peer="{{ .NeighborIP }}", vrf="{{ .NeighborVRF }}"} {{ .SessionUp }}
 	# HELP frrk8s_bgp_total_received Number of total BGP messages received
 	# TYPE frrk8s_bgp_total_received counter
 	frrk8s_bgp_total_received{peer="{{ .NeighborIP }}", vrf="{{ .NeighborVRF }}"} {{ .TotalReceived }}
@@ -62,6 +62,7 @@ var (
 		announcedPrefixes    int
 		receivedPrefixes     int
 		sessionUp            int
+		adminShutdown        bool
 		updatesTotal         int
 		updatesTotalReceived int
 		keepalivesSent       int
@@ -111,7 +112,49 @@ var (
 			totalSent:            15,
 			totalReceived:        15,
 		},
+		{
+			desc:          "Neighbor administratively shut down",
+			vtyshOutput:   neighborShutdown,
+			neighborIP:    "172.18.0.5:0",
+			neighborVRF:   "default",
+			sessionUp:     0,
+			adminShutdown: true,
+		},
 	}
+	neighborShutdown = `
+	{
+		"172.18.0.5":{
+		  "remoteAs":64512,
+		  "localAs":64513,
+		  "nbrExternalLink":true,
+		  "adminShutDown":true,
+		  "bgpVersion":4,
+		  "remoteRouterId":"0.0.0.0",
+		  "localRouterId":"172.18.0.3",
+		  "bgpState":"Idle",
+		  "bgpTimerLastRead":2000,
+		  "bgpTimerLastWrite":2000,
+		  "messageStats":{
+			"depthInq":0,
+			"depthOutq":0,
+			"opensSent":0,
+			"opensRecv":0,
+			"notificationsSent":0,
+			"notificationsRecv":0,
+			"updatesSent":0,
+			"updatesRecv":0,
+			"keepalivesSent":0,
+			"keepalivesRecv":0,
+			"routeRefreshSent":0,
+			"routeRefreshRecv":0,
+			"capabilitySent":0,
+			"capabilityRecv":0,
+			"totalSent":0,
+			"totalRecv":0
+		  },
+		  "addressFamilyInfo":{}
+		}
+	}`
 	neighborsIPv4Only = `
 	{
 		"172.18.0.4":{
@@ -456,6 +499,7 @@ func TestCollect(t *testing.T) {
 				"AnnouncedPrefixes":    tc.announcedPrefixes,
 				"ReceivedPrefixes":     tc.receivedPrefixes,
 				"SessionUp":            tc.sessionUp,
+				"AdminShutdown":        tc.adminShutdown,
 				"UpdatesTotal":         tc.updatesTotal,
 				"UpdatesTotalReceived": tc.updatesTotalReceived,
 				"KeepalivesReceived":   tc.keepalivesReceived,
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/go-kit/log/level"
+
+	"github.com/metallb/frr-k8s/internal/logging"
+	"github.com/metallb/frr-k8s/internal/reloader"
+	"github.com/metallb/frr-k8s/internal/version"
+)
+
+var (
+	sharedVolume = flag.String("shared-volume", "/etc/frr_reloader", "The volume shared with the daemon, containing the configuration file to reload.")
+	socket       = flag.String("socket", "", "The unix socket to listen on for reload requests. Defaults to reloader.sock in the shared volume.")
+	logLevel     = flag.String("log-level", "info", fmt.Sprintf("log level. must be one of: [%s]", logging.Levels.String()))
+)
+
+func main() {
+	flag.Parse()
+
+	logger, err := logging.Init(*logLevel)
+	if err != nil {
+		fmt.Printf("failed to initialize logging: %s\n", err)
+		os.Exit(1)
+	}
+
+	level.Info(logger).Log("version", version.Version(), "commit", version.CommitHash(), "branch", version.Branch(), "goversion", version.GoString(), "msg", "FRR reloader starting "+version.String())
+
+	socketPath := *socket
+	if socketPath == "" {
+		socketPath = filepath.Join(*sharedVolume, "reloader.sock")
+	}
+	// Remove the leftovers of a previous run, as listening would fail otherwise.
+	if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+		level.Error(logger).Log("op", "startup", "error", err, "socket", socketPath)
+		os.Exit(1)
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		level.Error(logger).Log("op", "startup", "error", err, "socket", socketPath)
+		os.Exit(1)
+	}
+
+	srv := &http.Server{
+		Handler: reloader.NewServer(*sharedVolume, logger).Handler(),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		level.Info(logger).Log("op", "shutdown", "msg", "caught an exit signal")
+		srv.Close()
+	}()
+
+	level.Info(logger).Log("op", "startup", "msg", "listening for reload requests", "socket", socketPath)
+	if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+		level.Error(logger).Log("error", err)
+		os.Exit(1)
+	}
+}
@@ -8,7 +8,10 @@ import (
 	"net"
 	"reflect"
 	"sort"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
 	"github.com/metallb/frr-k8s/internal/community"
@@ -16,41 +19,80 @@ import (
 	"github.com/metallb/frr-k8s/internal/ipfamily"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/ptr"
 )
 
 type ClusterResources struct {
 	FRRConfigs      []v1beta1.FRRConfiguration
 	PasswordSecrets map[string]corev1.Secret
+	Services        []corev1.Service
+	PodCIDRs        []string
+	Pods            []corev1.Pod
+	HealthyProbes   sets.Set[string]
+	Defaults        *v1beta1.FRRDefaultsSpec
+	Policy          *v1beta1.FRRPolicySpec
 }
 
 type namedRawConfig struct {
 	v1beta1.RawConfig
 	configName string
+	configKey  types.NamespacedName
 }
 
-func apiToFRR(resources ClusterResources, alwaysBlock []net.IPNet) (*frr.Config, error) {
+// apiToFRR converts the given resources to the FRR configuration. Along with the configuration,
+// it returns the conflicts between the configurations that were resolved by their priority.
+func apiToFRR(resources ClusterResources, alwaysBlock []net.IPNet) (*frr.Config, []string, error) {
 	res := &frr.Config{
 		Routers:     make([]*frr.RouterConfig, 0),
 		BFDProfiles: make([]frr.BFDProfile, 0),
+		Loglevel:    logLevelFor(resources.Defaults),
 	}
 
+	defaultsKey := types.NamespacedName{Name: v1beta1.FRRDefaultsName}
+	defaults, err := neighborDefaultsFor(resources.Defaults)
+	if err != nil {
+		return nil, nil, inObject(defaultsKey, atField(field.NewPath("spec"), err))
+	}
+	alwaysBlock, err = alwaysBlockFor(resources.Defaults, alwaysBlock)
+	if err != nil {
+		return nil, nil, inObject(defaultsKey, atField(field.NewPath("spec", "alwaysBlock"), err))
+	}
+	alwaysBlockFRR := alwaysBlockToFRR(alwaysBlock)
+
+	// The configurations are merged in decreasing order of priority, so that
+	// the conflicts are resolved in favor of the ones with the higher priority.
+	cfgs := make([]v1beta1.FRRConfiguration, len(resources.FRRConfigs))
+	copy(cfgs, resources.FRRConfigs)
+	sort.SliceStable(cfgs, func(i, j int) bool {
+		return cfgs[i].Spec.Priority > cfgs[j].Spec.Priority
+	})
+	mergeCtx := newMergeContext(defaults)
+
 	rawConfigs := make([]namedRawConfig, 0)
 	routersForVRF := map[string]*frr.RouterConfig{}
 	bfdProfilesAllConfigs := map[string]*frr.BFDProfile{}
-	for _, cfg := range resources.FRRConfigs {
+	for _, cfg := range cfgs {
+		cfgKey := types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}
+		bgpPath := field.NewPath("spec", "bgp")
 		bfdProfiles := map[string]*frr.BFDProfile{}
-		if cfg.Spec.Raw.Config != "" {
-			raw := namedRawConfig{RawConfig: cfg.Spec.Raw, configName: cfg.Name}
+		if cfg.Spec.Raw.Config != "" || len(cfg.Spec.Raw.Scoped) > 0 {
+			raw := namedRawConfig{RawConfig: cfg.Spec.Raw, configName: cfg.Name, configKey: cfgKey}
 			rawConfigs = append(rawConfigs, raw)
 		}
 
-		for _, b := range cfg.Spec.BGP.BFDProfiles {
+		for i, b := range cfg.Spec.BGP.BFDProfiles {
 			frrBFDProfile := bfdProfileToFRR(b)
+			profilePath := bgpPath.Child("bfdProfiles").Index(i).Child("name")
+			if err := validateProfileName(frrBFDProfile.Name); err != nil {
+				return nil, nil, inObject(cfgKey, invalidField(profilePath, "invalid bfd profile in config %s: %w", cfg.Name, err))
+			}
 			// Handling profiles local to the current config
 			if _, found := bfdProfiles[frrBFDProfile.Name]; found {
-				return nil, fmt.Errorf("duplicate bfd profile name %s in config %s", frrBFDProfile.Name, cfg.Name)
+				return nil, nil, inObject(cfgKey, newConversionError(ReasonDuplicate, profilePath,
+					"duplicate bfd profile name %s in config %s", frrBFDProfile.Name, cfg.Name))
 			}
 			bfdProfiles[frrBFDProfile.Name] = frrBFDProfile
 
@@ -58,7 +100,8 @@ func apiToFRR(resources ClusterResources, alwaysBlock []net.IPNet) (*frr.Config,
 			// values
 			old, found := bfdProfilesAllConfigs[frrBFDProfile.Name]
 			if found && !reflect.DeepEqual(old, frrBFDProfile) {
-				return nil, fmt.Errorf("duplicate bfd profile name %s with different values for config %s", frrBFDProfile.Name, cfg.Name)
+				return nil, nil, inObject(cfgKey, newConversionError(ReasonConflict, profilePath,
+					"duplicate bfd profile name %s with different values for config %s", frrBFDProfile.Name, cfg.Name))
 			}
 
 			if !found {
@@ -66,36 +109,66 @@ func apiToFRR(resources ClusterResources, alwaysBlock []net.IPNet) (*frr.Config,
 			}
 		}
 
-		alwaysBlockFRR := alwaysBlockToFRR(alwaysBlock)
-		for _, r := range cfg.Spec.BGP.Routers {
-			routerCfg, err := routerToFRRConfig(r, alwaysBlockFRR, resources.PasswordSecrets, bfdProfiles)
+		for i, r := range cfg.Spec.BGP.Routers {
+			routerCfg, err := routerToFRRConfig(r, bgpPath.Child("routers").Index(i), alwaysBlockFRR, resources, bfdProfiles)
 			if err != nil {
-				return nil, err
+				return nil, nil, inObject(cfgKey, err)
 			}
 
 			curr, ok := routersForVRF[r.VRF]
 			if !ok {
 				routersForVRF[r.VRF] = routerCfg
+				mergeCtx.addRouter(routerCfg, sourceForRouter(cfg, i))
 				continue
 			}
 
-			curr, err = mergeRouterConfigs(curr, routerCfg)
+			curr, err = mergeRouterConfigs(curr, routerCfg, sourceForRouter(cfg, i), mergeCtx)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 
 			routersForVRF[r.VRF] = curr
 		}
 	}
 
+	if defaults.bfdProfile != nil {
+		old, found := bfdProfilesAllConfigs[defaults.bfdProfile.Name]
+		if found && !reflect.DeepEqual(old, defaults.bfdProfile) {
+			return nil, nil, inObject(defaultsKey, newConversionError(ReasonConflict, field.NewPath("spec", "bfdProfile", "name"),
+				"bfd profile %s has different values than the default one", defaults.bfdProfile.Name))
+		}
+		bfdProfilesAllConfigs[defaults.bfdProfile.Name] = defaults.bfdProfile
+	}
+
+	for _, r := range routersForVRF {
+		for _, n := range r.Neighbors {
+			applyNeighborDefaults(n, defaults)
+		}
+	}
+
+	sortRawConfigs(rawConfigs)
+	if err := injectScopedRawConfigs(routersForVRF, rawConfigs); err != nil {
+		return nil, nil, err
+	}
+
 	res.Routers = sortMapPtr(routersForVRF)
-	res.ExtraConfig = joinRawConfigs(rawConfigs)
 	res.BFDProfiles = sortMap(bfdProfilesAllConfigs)
+	if err := rawConfigsConflicts(res, rawConfigs); err != nil {
+		return nil, nil, err
+	}
+	res.ExtraConfig = joinRawConfigs(rawConfigs)
 
-	return res, nil
+	return res, mergeCtx.warnings, nil
 }
 
-func routerToFRRConfig(r v1beta1.Router, alwaysBlock []frr.IncomingFilter, secrets map[string]corev1.Secret, bfdProfiles map[string]*frr.BFDProfile) (*frr.RouterConfig, error) {
+func routerToFRRConfig(r v1beta1.Router, fldPath *field.Path, alwaysBlock []frr.IncomingFilter, resources ClusterResources, bfdProfiles map[string]*frr.BFDProfile) (*frr.RouterConfig, error) {
+	if err := validateRouterID(r.ID); err != nil {
+		return nil, invalidField(fldPath.Child("id"), "invalid router id for router %d-%s: %w", r.ASN, r.VRF, err)
+	}
+	if err := validateVRFName(r.VRF); err != nil {
+		return nil, invalidField(fldPath.Child("vrf"), "invalid vrf for router %d: %w", r.ASN, err)
+	}
+
 	res := &frr.RouterConfig{
 		MyASN:        r.ASN,
 		RouterID:     r.ID,
@@ -105,7 +178,36 @@ func routerToFRRConfig(r v1beta1.Router, alwaysBlock []frr.IncomingFilter, secre
 		IPV6Prefixes: make([]string, 0),
 	}
 
-	for _, p := range r.Prefixes {
+	prefixes := make([]string, 0, len(r.Prefixes))
+	prefixes = append(prefixes, r.Prefixes...)
+	if r.ServiceSelector != nil {
+		servicePrefixes, err := prefixesForServices(resources.Services, r.ServiceSelector)
+		if err != nil {
+			return nil, invalidField(fldPath.Child("serviceSelector"), "failed to process services for router %d-%s: %w", r.ASN, r.VRF, err)
+		}
+		prefixes = appendMissing(prefixes, servicePrefixes...)
+	}
+	if r.AdvertisePodCIDRs {
+		prefixes = appendMissing(prefixes, resources.PodCIDRs...)
+	}
+
+	// The prefixes whose condition is not met are withdrawn, but they can still be
+	// referenced by the neighbors. Unconditional prefixes are always advertised.
+	withdrawn := sets.New[string]()
+	unconditional := sets.New(prefixes...)
+	for i, c := range r.ConditionalPrefixes {
+		met, err := conditionMet(c.Condition, resources)
+		if err != nil {
+			return nil, invalidField(fldPath.Child("conditionalPrefixes").Index(i).Child("condition"), "invalid condition for prefixes %v of router %d-%s: %w", c.Prefixes, r.ASN, r.VRF, err)
+		}
+		prefixes = appendMissing(prefixes, c.Prefixes...)
+		if !met {
+			withdrawn.Insert(c.Prefixes...)
+		}
+	}
+	withdrawn = withdrawn.Difference(unconditional)
+
+	for _, p := range prefixes {
 		family := ipfamily.ForCIDRString(p)
 		switch family {
 		case ipfamily.IPv4:
@@ -113,28 +215,36 @@ func routerToFRRConfig(r v1beta1.Router, alwaysBlock []frr.IncomingFilter, secre
 		case ipfamily.IPv6:
 			res.IPV6Prefixes = append(res.IPV6Prefixes, p)
 		case ipfamily.Unknown:
-			return nil, fmt.Errorf("unknown ipfamily for %s", p)
+			return nil, invalidField(fldPath.Child("prefixes"), "unknown ipfamily for %s", p)
 		}
 	}
 
-	for _, n := range r.Neighbors {
-		frrNeigh, err := neighborToFRR(n, res.IPV4Prefixes, res.IPV6Prefixes, alwaysBlock, r.VRF, secrets, bfdProfiles)
+	for i, n := range r.Neighbors {
+		frrNeigh, err := neighborToFRR(n, fldPath.Child("neighbors").Index(i), res.IPV4Prefixes, res.IPV6Prefixes, alwaysBlock, r.VRF, resources.PasswordSecrets, bfdProfiles)
 		if err != nil {
-			return nil, fmt.Errorf("failed to process neighbor %s for router %d-%s: %w", neighborName(n.ASN, n.Address), r.ASN, r.VRF, err)
+			return nil, withContext(err, "failed to process neighbor %s for router %d-%s", neighborName(n.ASN, n.Address), r.ASN, r.VRF)
 		}
 		res.Neighbors = append(res.Neighbors, frrNeigh)
 	}
 
+	res.IPV4Prefixes = removeAll(res.IPV4Prefixes, withdrawn)
+	res.IPV6Prefixes = removeAll(res.IPV6Prefixes, withdrawn)
+
+	// The asn is checked last, so that the other errors of the router are reported first.
+	if r.ASN == 0 && r.ASNFrom == "" {
+		return nil, invalidField(fldPath.Child("asn"), "router with vrf %q has neither asn nor asnFrom set", r.VRF)
+	}
 	return res, nil
 }
 
-func neighborToFRR(n v1beta1.Neighbor, ipv4Prefixes, ipv6Prefixes []string, alwaysBlock []frr.IncomingFilter, routerVRF string, passwordSecrets map[string]corev1.Secret, bfdProfiles map[string]*frr.BFDProfile) (*frr.NeighborConfig, error) {
+func neighborToFRR(n v1beta1.Neighbor, fldPath *field.Path, ipv4Prefixes, ipv6Prefixes []string, alwaysBlock []frr.IncomingFilter, routerVRF string, passwordSecrets map[string]corev1.Secret, bfdProfiles map[string]*frr.BFDProfile) (*frr.NeighborConfig, error) {
 	neighborFamily, err := ipfamily.ForAddresses(n.Address)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find ipfamily for %s, %w", n.Address, err)
+		return nil, invalidField(fldPath.Child("address"), "failed to find ipfamily for %s, %w", n.Address, err)
 	}
 	if _, ok := bfdProfiles[n.BFDProfile]; n.BFDProfile != "" && !ok {
-		return nil, fmt.Errorf("neighbor %s referencing non existing BFDProfile %s", neighborName(n.ASN, n.Address), n.BFDProfile)
+		return nil, newConversionError(ReasonNotFound, fldPath.Child("bfdProfile"),
+			"neighbor %s referencing non existing BFDProfile %s", neighborName(n.ASN, n.Address), n.BFDProfile)
 	}
 	res := &frr.NeighborConfig{
 		Name:         neighborName(n.ASN, n.Address),
@@ -147,36 +257,50 @@ func neighborToFRR(n v1beta1.Neighbor, ipv4Prefixes, ipv6Prefixes []string, alwa
 		VRFName:      routerVRF,
 		AlwaysBlock:  alwaysBlock,
 	}
-	res.HoldTime, res.KeepaliveTime, err = parseTimers(n.HoldTime, n.KeepaliveTime)
+	if err := validateDisabledMessage(n.DisabledMessage); err != nil {
+		return nil, invalidField(fldPath.Child("disabledMessage"), "invalid disabled message for neighbor %s: %w", neighborName(n.ASN, n.Address), err)
+	}
+	if n.Disabled {
+		res.Disabled = true
+		res.DisabledMessage = n.DisabledMessage
+	}
+	res.HoldTime, res.KeepaliveTime, err = parseTimers(n.HoldTime, n.KeepaliveTime, fldPath)
 	if err != nil {
-		return nil, fmt.Errorf("invalid timers for neighbor %s, err: %w", neighborName(n.ASN, n.Address), err)
+		return nil, err
 	}
 
 	if n.ConnectTime != nil {
 		res.ConnectTime = ptr.To(uint64(n.ConnectTime.Duration / time.Second))
 	}
 
-	res.Password, err = passwordForNeighbor(n, passwordSecrets)
+	res.Password, err = passwordForNeighbor(n, passwordSecrets, fldPath)
 	if err != nil {
 		return nil, err
 	}
 	res.Outgoing, err = toAdvertiseToFRR(n.ToAdvertise, ipv4Prefixes, ipv6Prefixes)
 	if err != nil {
-		return nil, err
+		return nil, atField(fldPath.Child("toAdvertise"), err)
 	}
-	res.Incoming, err = toReceiveToFRR(n.ToReceive)
+	res.Incoming, err = toReceiveToFRR(n.ToReceive, fldPath.Child("toReceive"))
 	if err != nil {
 		return nil, err
 	}
+	// The asn is checked last, so that the other errors of the neighbor are reported first.
+	if n.ASN == 0 && n.ASNFrom == "" {
+		return nil, invalidField(fldPath.Child("asn"), "neighbor %s has neither asn nor asnFrom set", n.Address)
+	}
 	return res, nil
 }
 
-func passwordForNeighbor(n v1beta1.Neighbor, passwordSecrets map[string]corev1.Secret) (string, error) {
+func passwordForNeighbor(n v1beta1.Neighbor, passwordSecrets map[string]corev1.Secret, fldPath *field.Path) (string, error) {
 	if n.Password != "" && n.PasswordSecret.Name != "" {
-		return "", fmt.Errorf("neighbor %s specifies both cleartext password and secret ref", neighborName(n.ASN, n.Address))
+		return "", invalidField(fldPath.Child("passwordSecret"), "neighbor %s specifies both cleartext password and secret ref", neighborName(n.ASN, n.Address))
 	}
 
 	if n.Password != "" {
+		if err := validatePassword(n.Password); err != nil {
+			return "", invalidField(fldPath.Child("password"), "invalid password for neighbor %s: %w", neighborName(n.ASN, n.Address), err)
+		}
 		return n.Password, nil
 	}
 
@@ -186,15 +310,22 @@ func passwordForNeighbor(n v1beta1.Neighbor, passwordSecrets map[string]corev1.S
 
 	secret, ok := passwordSecrets[n.PasswordSecret.Name]
 	if !ok {
-		return "", TransientError{Message: fmt.Sprintf("secret %s not found for neighbor %s", n.PasswordSecret.Name, neighborName(n.ASN, n.Address))}
+		return "", &ConversionError{
+			Reason: ReasonNotFound,
+			Field:  fldPath.Child("passwordSecret"),
+			Err:    TransientError{Message: fmt.Sprintf("secret %s not found for neighbor %s", n.PasswordSecret.Name, neighborName(n.ASN, n.Address))},
+		}
 	}
 	if secret.Type != corev1.SecretTypeBasicAuth {
-		return "", fmt.Errorf("secret type mismatch on %q/%q, type %q is expected ", secret.Namespace,
+		return "", invalidField(fldPath.Child("passwordSecret"), "secret type mismatch on %q/%q, type %q is expected ", secret.Namespace,
 			secret.Name, corev1.SecretTypeBasicAuth)
 	}
 	srcPass, ok := secret.Data["password"]
 	if !ok {
-		return "", fmt.Errorf("password field not specified in the secret %q/%q", secret.Namespace, secret.Name)
+		return "", invalidField(fldPath.Child("passwordSecret"), "password field not specified in the secret %q/%q", secret.Namespace, secret.Name)
+	}
+	if err := validatePassword(string(srcPass)); err != nil {
+		return "", invalidField(fldPath.Child("passwordSecret"), "invalid password in the secret %q/%q: %w", secret.Namespace, secret.Name, err)
 	}
 	return string(srcPass), nil
 }
@@ -314,7 +445,7 @@ func setLocalPrefToAdvertisements(advs map[string]*frr.OutgoingFilter, localPref
 	return nil
 }
 
-func toReceiveToFRR(toReceive v1beta1.Receive) (frr.AllowedIn, error) {
+func toReceiveToFRR(toReceive v1beta1.Receive, fldPath *field.Path) (frr.AllowedIn, error) {
 	res := frr.AllowedIn{
 		PrefixesV4: make([]frr.IncomingFilter, 0),
 		PrefixesV6: make([]frr.IncomingFilter, 0),
@@ -323,8 +454,8 @@ func toReceiveToFRR(toReceive v1beta1.Receive) (frr.AllowedIn, error) {
 		res.All = true
 		return res, nil
 	}
-	for _, s := range toReceive.Allowed.Prefixes {
-		filter, err := filterForSelector(s)
+	for i, s := range toReceive.Allowed.Prefixes {
+		filter, err := filterForSelector(s, fldPath.Child("allowed", "prefixes").Index(i))
 		if err != nil {
 			return frr.AllowedIn{}, err
 		}
@@ -343,15 +474,15 @@ func toReceiveToFRR(toReceive v1beta1.Receive) (frr.AllowedIn, error) {
 	return res, nil
 }
 
-func filterForSelector(selector v1beta1.PrefixSelector) (frr.IncomingFilter, error) {
+func filterForSelector(selector v1beta1.PrefixSelector, fldPath *field.Path) (frr.IncomingFilter, error) {
 	_, cidr, err := net.ParseCIDR(selector.Prefix)
 	if err != nil {
-		return frr.IncomingFilter{}, fmt.Errorf("failed to parse prefix %s: %w", selector.Prefix, err)
+		return frr.IncomingFilter{}, invalidField(fldPath.Child("prefix"), "failed to parse prefix %s: %w", selector.Prefix, err)
 	}
 	maskLen, _ := cidr.Mask.Size()
 	err = validateSelectorLengths(maskLen, selector.LE, selector.GE)
 	if err != nil {
-		return frr.IncomingFilter{}, err
+		return frr.IncomingFilter{}, atField(fldPath, err)
 	}
 
 	family := ipfamily.ForCIDRString(selector.Prefix)
@@ -382,6 +513,25 @@ func validateSelectorLengths(mask int, le, ge uint32) error {
 	return nil
 }
 
+// maxDisabledMessageLength is the maximum length of the message sent
+// to a neighbor when disabling the session.
+const maxDisabledMessageLength = 128
+
+// validateDisabledMessage checks the given message can be written in the
+// neighbor shutdown command, which would be broken (or extended with other
+// commands) by control characters such as newlines.
+func validateDisabledMessage(message string) error {
+	if utf8.RuneCountInString(message) > maxDisabledMessageLength {
+		return fmt.Errorf("longer than %d characters", maxDisabledMessageLength)
+	}
+	for _, c := range message {
+		if !unicode.IsPrint(c) && c != ' ' {
+			return fmt.Errorf("contains the non printable character %q", c)
+		}
+	}
+	return nil
+}
+
 func neighborName(ASN uint32, peerAddr string) string {
 	return fmt.Sprintf("%d@%s", ASN, peerAddr)
 }
@@ -515,21 +665,143 @@ func sortMapPtr[T any](toSort map[string]*T) []*T {
 	return res
 }
 
-func joinRawConfigs(raw []namedRawConfig) string {
+// sortRawConfigs sorts the given raw configs in the order they are rendered.
+func sortRawConfigs(raw []namedRawConfig) {
 	sort.Slice(raw, func(i, j int) bool {
 		if raw[i].Priority == raw[j].Priority {
 			return raw[i].configName < raw[j].configName
 		}
 		return raw[i].Priority < raw[j].Priority
 	})
+}
+
+func joinRawConfigs(raw []namedRawConfig) string {
 	res := bytes.Buffer{}
 	for _, r := range raw {
+		if r.Config == "" {
+			continue
+		}
 		res.Write([]byte(r.Config))
 		res.WriteString("\n")
 	}
 	return res.String()
 }
 
+// injectScopedRawConfigs injects the scoped snippets of the given raw configs in the
+// sections of the routers and of the neighbors they target.
+func injectScopedRawConfigs(routers map[string]*frr.RouterConfig, raw []namedRawConfig) error {
+	for _, r := range raw {
+		for i, s := range r.Scoped {
+			fldPath := field.NewPath("spec", "raw", "scoped").Index(i)
+			router, ok := routers[s.VRF]
+			if !ok || router.MyASN != s.ASN {
+				return inObject(r.configKey, newConversionError(ReasonNotFound, fldPath,
+					"raw config scoped to router %d-%s, which is not configured", s.ASN, s.VRF))
+			}
+
+			target := &router.RawConfig
+			if s.Neighbor != "" {
+				neigh := neighborWithAddress(router, s.Neighbor)
+				if neigh == nil {
+					return inObject(r.configKey, newConversionError(ReasonNotFound, fldPath.Child("neighbor"),
+						"raw config scoped to neighbor %s of router %d-%s, which is not configured", s.Neighbor, s.ASN, s.VRF))
+				}
+				target = &neigh.RawConfig
+			}
+
+			switch ipfamily.Family(s.AddressFamily) {
+			case "":
+				target.Config = appendRawConfig(target.Config, s.Config)
+			case ipfamily.IPv4:
+				target.IPV4 = appendRawConfig(target.IPV4, s.Config)
+			case ipfamily.IPv6:
+				target.IPV6 = appendRawConfig(target.IPV6, s.Config)
+			default:
+				return inObject(r.configKey, invalidField(fldPath.Child("addressFamily"), "unknown address family %s", s.AddressFamily))
+			}
+		}
+	}
+	return nil
+}
+
+// rawConfigsConflicts checks that the raw configurations do not define any of the
+// route-maps, prefix-lists, community-lists or bfd profiles the generated configuration
+// relies on, as doing so would silently alter the generated policies.
+func rawConfigsConflicts(config *frr.Config, raw []namedRawConfig) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	generated, err := frr.GeneratedObjects(config)
+	if err != nil {
+		return err
+	}
+
+	conflict := func(snippet string) *frr.NamedObject {
+		for _, o := range frr.DefinedObjects(snippet) {
+			if generated[o] {
+				return &o
+			}
+		}
+		return nil
+	}
+	rawPath := field.NewPath("spec", "raw")
+	for _, r := range raw {
+		if o := conflict(r.Config); o != nil {
+			return inObject(r.configKey, newConversionError(ReasonConflict, rawPath.Child("rawConfig"),
+				"%s %s is already defined by the generated configuration", o.Kind, o.Name))
+		}
+		for i, s := range r.Scoped {
+			if o := conflict(s.Config); o != nil {
+				return inObject(r.configKey, newConversionError(ReasonConflict, rawPath.Child("scoped").Index(i).Child("rawConfig"),
+					"%s %s is already defined by the generated configuration", o.Kind, o.Name))
+			}
+		}
+	}
+	return nil
+}
+
+func neighborWithAddress(router *frr.RouterConfig, address string) *frr.NeighborConfig {
+	for _, n := range router.Neighbors {
+		if n.Addr == address {
+			return n
+		}
+	}
+	return nil
+}
+
+func appendRawConfig(curr, toAppend string) string {
+	toAppend = strings.TrimRight(toAppend, "\n")
+	if curr == "" {
+		return toAppend
+	}
+	return curr + "\n" + toAppend
+}
+
+// appendMissing appends to the given list the elements that are not already part of it.
+func appendMissing(list []string, toAdd ...string) []string {
+	existing := sets.New(list...)
+	for _, e := range toAdd {
+		if existing.Has(e) {
+			continue
+		}
+		existing.Insert(e)
+		list = append(list, e)
+	}
+	return list
+}
+
+// removeAll returns the elements of the given list that are not part of the given set.
+func removeAll(list []string, toRemove sets.Set[string]) []string {
+	res := make([]string, 0, len(list))
+	for _, e := range list {
+		if toRemove.Has(e) {
+			continue
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
 func alwaysBlockToFRR(cidrs []net.IPNet) []frr.IncomingFilter {
 	res := make([]frr.IncomingFilter, 0, len(cidrs))
 	for _, c := range cidrs {
@@ -544,9 +816,13 @@ func alwaysBlockToFRR(cidrs []net.IPNet) []frr.IncomingFilter {
 	return res
 }
 
-func parseTimers(ht, ka *v1.Duration) (*uint64, *uint64, error) {
-	if ht == nil && ka != nil || ht != nil && ka == nil {
-		return nil, nil, fmt.Errorf("one of KeepaliveTime/HoldTime specified, both must be set or none")
+// parseTimers validates the hold and keepalive times of the neighbor with the given path.
+func parseTimers(ht, ka *v1.Duration, fldPath *field.Path) (*uint64, *uint64, error) {
+	if ht == nil && ka != nil {
+		return nil, nil, invalidField(fldPath.Child("holdTime"), "one of KeepaliveTime/HoldTime specified, both must be set or none")
+	}
+	if ht != nil && ka == nil {
+		return nil, nil, invalidField(fldPath.Child("keepaliveTime"), "one of KeepaliveTime/HoldTime specified, both must be set or none")
 	}
 
 	if ht == nil && ka == nil {
@@ -558,11 +834,11 @@ func parseTimers(ht, ka *v1.Duration) (*uint64, *uint64, error) {
 
 	rounded := time.Duration(int(ht.Seconds())) * time.Second
 	if rounded != 0 && rounded < 3*time.Second {
-		return nil, nil, fmt.Errorf("invalid hold time %q: must be 0 or >=3s", ht)
+		return nil, nil, invalidField(fldPath.Child("holdTime"), "invalid hold time %q: must be 0 or >=3s", ht)
 	}
 
 	if keepaliveTime > holdTime {
-		return nil, nil, fmt.Errorf("invalid keepaliveTime %q, must be lower than holdTime %q", ka, ht)
+		return nil, nil, invalidField(fldPath.Child("keepaliveTime"), "invalid keepaliveTime %q, must be lower than holdTime %q", ka, ht)
 	}
 
 	htSeconds := uint64(holdTime / time.Second)
@@ -12,10 +12,13 @@ import (
 	"github.com/google/go-cmp/cmp"
 	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
 	"github.com/metallb/frr-k8s/internal/frr"
+	"github.com/metallb/frr-k8s/internal/health"
 	"github.com/metallb/frr-k8s/internal/ipfamily"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/ptr"
 )
 
@@ -27,8 +30,14 @@ func TestConversion(t *testing.T) {
 		name        string
 		fromK8s     []v1beta1.FRRConfiguration
 		secrets     map[string]v1.Secret
+		services    []v1.Service
+		podCIDRs    []string
+		pods        []v1.Pod
+		probes      sets.Set[string]
 		alwaysBlock []net.IPNet
+		defaults    *v1beta1.FRRDefaultsSpec
 		expected    *frr.Config
+		warnings    []string
 		err         error
 	}{
 
@@ -1967,6 +1976,135 @@ func TestConversion(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			name: "Single Router and scoped injection",
+			fromK8s: []v1beta1.FRRConfiguration{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "second",
+					},
+					Spec: v1beta1.FRRConfigurationSpec{
+						BGP: v1beta1.BGPConfig{
+							Routers: []v1beta1.Router{
+								{
+									ASN: 65001,
+									Neighbors: []v1beta1.Neighbor{
+										{
+											ASN:     65002,
+											Address: "192.0.2.2",
+										},
+									},
+								},
+							},
+						},
+						Raw: v1beta1.RawConfig{
+							Scoped: []v1beta1.ScopedRawConfig{
+								{
+									ASN:    65001,
+									Config: "  bgp bestpath as-path multipath-relax\n",
+								},
+								{
+									ASN:           65001,
+									AddressFamily: "ipv4",
+									Config:        "    maximum-paths 8",
+								},
+								{
+									ASN:           65001,
+									Neighbor:      "192.0.2.2",
+									AddressFamily: "ipv4",
+									Config:        "    neighbor 192.0.2.2 allowas-in 1",
+								},
+							},
+						},
+					},
+				}, {
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "first",
+					},
+					Spec: v1beta1.FRRConfigurationSpec{
+						Raw: v1beta1.RawConfig{
+							Config: "foo",
+							Scoped: []v1beta1.ScopedRawConfig{
+								{
+									ASN:    65001,
+									Config: "  bgp router-id 192.0.2.1",
+								},
+								{
+									ASN:      65001,
+									Neighbor: "192.0.2.2",
+									Config:   "  neighbor 192.0.2.2 description foo",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &frr.Config{
+				Routers: []*frr.RouterConfig{
+					{
+						MyASN: 65001,
+						Neighbors: []*frr.NeighborConfig{
+							{
+								IPFamily: ipfamily.IPv4,
+								Name:     "65002@192.0.2.2",
+								ASN:      65002,
+								Addr:     "192.0.2.2",
+								Outgoing: frr.AllowedOut{
+									PrefixesV4: []frr.OutgoingFilter{},
+									PrefixesV6: []frr.OutgoingFilter{},
+								},
+								Incoming: frr.AllowedIn{
+									PrefixesV4: []frr.IncomingFilter{},
+									PrefixesV6: []frr.IncomingFilter{},
+								},
+								AlwaysBlock: []frr.IncomingFilter{},
+								RawConfig: frr.ScopedRawConfig{
+									Config: "  neighbor 192.0.2.2 description foo",
+									IPV4:   "    neighbor 192.0.2.2 allowas-in 1",
+								},
+							},
+						},
+						VRF:          "",
+						IPV4Prefixes: []string{},
+						IPV6Prefixes: []string{},
+						RawConfig: frr.ScopedRawConfig{
+							Config: "  bgp router-id 192.0.2.1\n  bgp bestpath as-path multipath-relax",
+							IPV4:   "    maximum-paths 8",
+						},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				ExtraConfig: "foo\n",
+			},
+			err: nil,
+		},
+		{
+			name: "Scoped injection for a missing neighbor",
+			fromK8s: []v1beta1.FRRConfiguration{
+				{
+					Spec: v1beta1.FRRConfigurationSpec{
+						BGP: v1beta1.BGPConfig{
+							Routers: []v1beta1.Router{
+								{
+									ASN: 65001,
+								},
+							},
+						},
+						Raw: v1beta1.RawConfig{
+							Scoped: []v1beta1.ScopedRawConfig{
+								{
+									ASN:      65001,
+									Neighbor: "192.0.2.2",
+									Config:   "  neighbor 192.0.2.2 description foo",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: nil,
+			err:      errors.New("raw config scoped to neighbor 192.0.2.2 of router 65001-, which is not configured"),
+		},
 		{
 			name: "Neighbor with BFDProfile",
 			fromK8s: []v1beta1.FRRConfiguration{
@@ -2403,23 +2541,698 @@ func TestConversion(t *testing.T) {
 			},
 			err: nil,
 		},
-	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			resources := ClusterResources{
-				FRRConfigs:      test.fromK8s,
-				PasswordSecrets: test.secrets,
-			}
-			frr, err := apiToFRR(resources, test.alwaysBlock)
-			if test.err != nil && err == nil {
-				t.Fatalf("expected error, got nil")
-			}
-			if test.err == nil && err != nil {
-				t.Fatalf("expected no error, got %v", err)
-			}
-			if diff := cmp.Diff(frr, test.expected); diff != "" {
-				t.Fatalf("config different from expected: %s", diff)
+		{
+			name: "With cluster wide defaults",
+			fromK8s: []v1beta1.FRRConfiguration{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "config1",
+					},
+					Spec: v1beta1.FRRConfigurationSpec{
+						BGP: v1beta1.BGPConfig{
+							Routers: []v1beta1.Router{
+								{
+									ASN: 65010,
+									Neighbors: []v1beta1.Neighbor{
+										{
+											ASN:           65011,
+											Address:       "192.0.2.6",
+											HoldTime:      &metav1.Duration{Duration: 90 * time.Second},
+											KeepaliveTime: &metav1.Duration{Duration: 30 * time.Second},
+										},
+										{
+											ASN:     65012,
+											Address: "192.0.2.7",
+											Port:    ptr.To[uint16](179),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "config2",
+					},
+					Spec: v1beta1.FRRConfigurationSpec{
+						BGP: v1beta1.BGPConfig{
+							Routers: []v1beta1.Router{
+								{
+									ASN: 65010,
+									Neighbors: []v1beta1.Neighbor{
+										{
+											ASN:     65011,
+											Address: "192.0.2.6",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			secrets:     map[string]v1.Secret{},
+			alwaysBlock: []net.IPNet{*ipv6CIDR},
+			defaults: &v1beta1.FRRDefaultsSpec{
+				HoldTime:      &metav1.Duration{Duration: 90 * time.Second},
+				KeepaliveTime: &metav1.Duration{Duration: 30 * time.Second},
+				Port:          ptr.To[uint16](1790),
+				BFDProfile: &v1beta1.BFDProfile{
+					Name:            "default-bfd",
+					ReceiveInterval: ptr.To[uint32](300),
+				},
+				AlwaysBlock: []v1beta1.CIDR{"192.168.1.0/24"},
+				LogLevel:    "debug",
+			},
+			expected: &frr.Config{
+				Loglevel: "debugging",
+				Routers: []*frr.RouterConfig{
+					{
+						MyASN: 65010,
+						Neighbors: []*frr.NeighborConfig{
+							{
+								IPFamily:      ipfamily.IPv4,
+								Name:          "65011@192.0.2.6",
+								ASN:           65011,
+								Addr:          "192.0.2.6",
+								Port:          ptr.To[uint16](1790),
+								HoldTime:      ptr.To[uint64](90),
+								KeepaliveTime: ptr.To[uint64](30),
+								BFDProfile:    "default-bfd",
+								Outgoing: frr.AllowedOut{
+									PrefixesV4: []frr.OutgoingFilter{},
+									PrefixesV6: []frr.OutgoingFilter{},
+								},
+								Incoming: frr.AllowedIn{
+									PrefixesV4: []frr.IncomingFilter{},
+									PrefixesV6: []frr.IncomingFilter{},
+								},
+								AlwaysBlock: []frr.IncomingFilter{
+									{
+										IPFamily: ipfamily.IPv6,
+										Prefix:   "fc00:f853:ccd:e800::/64",
+										LE:       uint32(128),
+									}, {
+										IPFamily: ipfamily.IPv4,
+										Prefix:   "192.168.1.0/24",
+										LE:       uint32(32),
+									},
+								},
+							},
+							{
+								IPFamily:      ipfamily.IPv4,
+								Name:          "65012@192.0.2.7",
+								ASN:           65012,
+								Addr:          "192.0.2.7",
+								Port:          ptr.To[uint16](179),
+								HoldTime:      ptr.To[uint64](90),
+								KeepaliveTime: ptr.To[uint64](30),
+								BFDProfile:    "default-bfd",
+								Outgoing: frr.AllowedOut{
+									PrefixesV4: []frr.OutgoingFilter{},
+									PrefixesV6: []frr.OutgoingFilter{},
+								},
+								Incoming: frr.AllowedIn{
+									PrefixesV4: []frr.IncomingFilter{},
+									PrefixesV6: []frr.IncomingFilter{},
+								},
+								AlwaysBlock: []frr.IncomingFilter{
+									{
+										IPFamily: ipfamily.IPv6,
+										Prefix:   "fc00:f853:ccd:e800::/64",
+										LE:       uint32(128),
+									}, {
+										IPFamily: ipfamily.IPv4,
+										Prefix:   "192.168.1.0/24",
+										LE:       uint32(32),
+									},
+								},
+							},
+						},
+						IPV4Prefixes: []string{},
+						IPV6Prefixes: []string{},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{
+					{
+						Name:            "default-bfd",
+						ReceiveInterval: ptr.To[uint32](300),
+					},
+				},
+			},
+			err: nil,
+		},
+		{
+			name: "Conflicting neighbor resolved by priority",
+			fromK8s: []v1beta1.FRRConfiguration{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "low",
+					},
+					Spec: v1beta1.FRRConfigurationSpec{
+						BGP: v1beta1.BGPConfig{
+							Routers: []v1beta1.Router{
+								{
+									ASN: 65010,
+									Neighbors: []v1beta1.Neighbor{
+										{
+											ASN:     65011,
+											Address: "192.0.2.6",
+											Port:    ptr.To[uint16](1790),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "high",
+					},
+					Spec: v1beta1.FRRConfigurationSpec{
+						Priority: 10,
+						BGP: v1beta1.BGPConfig{
+							Routers: []v1beta1.Router{
+								{
+									ASN: 65010,
+									Neighbors: []v1beta1.Neighbor{
+										{
+											ASN:     65011,
+											Address: "192.0.2.6",
+											Port:    ptr.To[uint16](1800),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			secrets: map[string]v1.Secret{},
+			expected: &frr.Config{
+				Routers: []*frr.RouterConfig{
+					{
+						MyASN: 65010,
+						Neighbors: []*frr.NeighborConfig{
+							{
+								IPFamily: ipfamily.IPv4,
+								Name:     "65011@192.0.2.6",
+								ASN:      65011,
+								Addr:     "192.0.2.6",
+								Port:     ptr.To[uint16](1800),
+								Outgoing: frr.AllowedOut{
+									PrefixesV4: []frr.OutgoingFilter{},
+									PrefixesV6: []frr.OutgoingFilter{},
+								},
+								Incoming: frr.AllowedIn{
+									PrefixesV4: []frr.IncomingFilter{},
+									PrefixesV6: []frr.IncomingFilter{},
+								},
+								AlwaysBlock: []frr.IncomingFilter{},
+							},
+						},
+						IPV4Prefixes: []string{},
+						IPV6Prefixes: []string{},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+			},
+			warnings: []string{"multiple ports specified for neighbor 192.0.2.6 at vrf  (set by /high spec.bgp.routers[0].neighbors[0].port, /low spec.bgp.routers[0].neighbors[0].port), using the value of /high with priority 10"},
+		},
+		{
+			name: "Router advertising services",
+			fromK8s: []v1beta1.FRRConfiguration{
+				{
+					Spec: v1beta1.FRRConfigurationSpec{
+						BGP: v1beta1.BGPConfig{
+							Routers: []v1beta1.Router{
+								{
+									ASN:      65001,
+									Prefixes: []string{"192.0.2.0/24", "192.168.10.1/32"},
+									ServiceSelector: &metav1.LabelSelector{
+										MatchLabels: map[string]string{"advertise": "true"},
+									},
+									Neighbors: []v1beta1.Neighbor{
+										{
+											ASN:     65002,
+											Address: "192.0.2.2",
+											ToAdvertise: v1beta1.Advertise{
+												Allowed: v1beta1.AllowedOutPrefixes{
+													Mode: v1beta1.AllowAll,
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			secrets: map[string]v1.Secret{},
+			services: []v1.Service{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "svc1",
+						Namespace: "default",
+						Labels:    map[string]string{"advertise": "true"},
+					},
+					Spec: v1.ServiceSpec{
+						ExternalIPs: []string{"192.168.10.1"},
+					},
+					Status: v1.ServiceStatus{
+						LoadBalancer: v1.LoadBalancerStatus{
+							Ingress: []v1.LoadBalancerIngress{
+								{IP: "192.168.10.2"},
+								{IP: "2001:db8::10"},
+							},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "svc2",
+						Namespace: "default",
+					},
+					Status: v1.ServiceStatus{
+						LoadBalancer: v1.LoadBalancerStatus{
+							Ingress: []v1.LoadBalancerIngress{
+								{IP: "192.168.10.3"},
+							},
+						},
+					},
+				},
+			},
+			expected: &frr.Config{
+				Routers: []*frr.RouterConfig{
+					{
+						MyASN: 65001,
+						Neighbors: []*frr.NeighborConfig{
+							{
+								IPFamily: ipfamily.IPv4,
+								Name:     "65002@192.0.2.2",
+								ASN:      65002,
+								Addr:     "192.0.2.2",
+								Outgoing: frr.AllowedOut{
+									PrefixesV4: []frr.OutgoingFilter{
+										{
+											IPFamily: ipfamily.IPv4,
+											Prefix:   "192.0.2.0/24",
+										},
+										{
+											IPFamily: ipfamily.IPv4,
+											Prefix:   "192.168.10.1/32",
+										},
+										{
+											IPFamily: ipfamily.IPv4,
+											Prefix:   "192.168.10.2/32",
+										},
+									},
+									PrefixesV6: []frr.OutgoingFilter{
+										{
+											IPFamily: ipfamily.IPv6,
+											Prefix:   "2001:db8::10/128",
+										},
+									},
+								},
+								Incoming: frr.AllowedIn{
+									PrefixesV4: []frr.IncomingFilter{},
+									PrefixesV6: []frr.IncomingFilter{},
+								},
+								AlwaysBlock: []frr.IncomingFilter{},
+							},
+						},
+						IPV4Prefixes: []string{"192.0.2.0/24", "192.168.10.1/32", "192.168.10.2/32"},
+						IPV6Prefixes: []string{"2001:db8::10/128"},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+			},
+			err: nil,
+		},
+		{
+			name: "Router with invalid service selector",
+			fromK8s: []v1beta1.FRRConfiguration{
+				{
+					Spec: v1beta1.FRRConfigurationSpec{
+						BGP: v1beta1.BGPConfig{
+							Routers: []v1beta1.Router{
+								{
+									ASN: 65001,
+									ServiceSelector: &metav1.LabelSelector{
+										MatchExpressions: []metav1.LabelSelectorRequirement{
+											{
+												Key:      "advertise",
+												Operator: "invalid",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			secrets:  map[string]v1.Secret{},
+			expected: nil,
+			err:      errors.New("invalid service selector"),
+		},
+		{
+			name: "Router with conditional prefixes",
+			fromK8s: []v1beta1.FRRConfiguration{
+				{
+					Spec: v1beta1.FRRConfigurationSpec{
+						BGP: v1beta1.BGPConfig{
+							Routers: []v1beta1.Router{
+								{
+									ASN:      65001,
+									Prefixes: []string{"192.0.2.0/24", "192.0.4.1/32"},
+									ConditionalPrefixes: []v1beta1.ConditionalPrefixes{
+										{
+											Prefixes: []string{"192.0.3.1/32", "2001:db8::1/128"},
+											Condition: v1beta1.PrefixCondition{
+												Pod: &v1beta1.PodCondition{
+													Namespace: "backend",
+													Selector: metav1.LabelSelector{
+														MatchLabels: map[string]string{"app": "backend"},
+													},
+												},
+											},
+										},
+										{
+											Prefixes: []string{"192.0.3.2/32"},
+											Condition: v1beta1.PrefixCondition{
+												Pod: &v1beta1.PodCondition{
+													Selector: metav1.LabelSelector{
+														MatchLabels: map[string]string{"app": "notready"},
+													},
+												},
+											},
+										},
+										{
+											Prefixes: []string{"192.0.3.3/32"},
+											Condition: v1beta1.PrefixCondition{
+												Probe: &v1beta1.ProbeCondition{
+													HTTPGet: &v1beta1.HTTPGetProbe{Port: 8080, Path: "/healthz"},
+												},
+											},
+										},
+										{
+											Prefixes: []string{"192.0.3.4/32", "192.0.4.1/32"},
+											Condition: v1beta1.PrefixCondition{
+												Probe: &v1beta1.ProbeCondition{
+													TCPSocket: &v1beta1.TCPSocketProbe{Port: 8081},
+												},
+											},
+										},
+									},
+									Neighbors: []v1beta1.Neighbor{
+										{
+											ASN:     65002,
+											Address: "192.0.2.2",
+											ToAdvertise: v1beta1.Advertise{
+												Allowed: v1beta1.AllowedOutPrefixes{
+													Prefixes: []string{"192.0.3.1/32", "192.0.3.2/32", "192.0.3.4/32"},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			secrets: map[string]v1.Secret{},
+			pods: []v1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "backend", Labels: map[string]string{"app": "backend"}},
+					Status: v1.PodStatus{
+						Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "notready", Namespace: "backend", Labels: map[string]string{"app": "notready"}},
+					Status: v1.PodStatus{
+						Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionFalse}},
+					},
+				},
+			},
+			probes: sets.New(health.Probe{
+				Type:             health.HTTPProbe,
+				Host:             "127.0.0.1",
+				Port:             8080,
+				Path:             "/healthz",
+				Period:           10 * time.Second,
+				Timeout:          time.Second,
+				FailureThreshold: 3,
+			}.Key()),
+			expected: &frr.Config{
+				Routers: []*frr.RouterConfig{
+					{
+						MyASN: 65001,
+						Neighbors: []*frr.NeighborConfig{
+							{
+								IPFamily: ipfamily.IPv4,
+								Name:     "65002@192.0.2.2",
+								ASN:      65002,
+								Addr:     "192.0.2.2",
+								Outgoing: frr.AllowedOut{
+									PrefixesV4: []frr.OutgoingFilter{
+										{
+											IPFamily: ipfamily.IPv4,
+											Prefix:   "192.0.3.1/32",
+										},
+										{
+											IPFamily: ipfamily.IPv4,
+											Prefix:   "192.0.3.2/32",
+										},
+										{
+											IPFamily: ipfamily.IPv4,
+											Prefix:   "192.0.3.4/32",
+										},
+									},
+									PrefixesV6: []frr.OutgoingFilter{},
+								},
+								Incoming: frr.AllowedIn{
+									PrefixesV4: []frr.IncomingFilter{},
+									PrefixesV6: []frr.IncomingFilter{},
+								},
+								AlwaysBlock: []frr.IncomingFilter{},
+							},
+						},
+						IPV4Prefixes: []string{"192.0.2.0/24", "192.0.4.1/32", "192.0.3.1/32", "192.0.3.3/32"},
+						IPV6Prefixes: []string{"2001:db8::1/128"},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+			},
+			err: nil,
+		},
+		{
+			name: "Router with invalid prefix condition",
+			fromK8s: []v1beta1.FRRConfiguration{
+				{
+					Spec: v1beta1.FRRConfigurationSpec{
+						BGP: v1beta1.BGPConfig{
+							Routers: []v1beta1.Router{
+								{
+									ASN: 65001,
+									ConditionalPrefixes: []v1beta1.ConditionalPrefixes{
+										{
+											Prefixes:  []string{"192.0.3.1/32"},
+											Condition: v1beta1.PrefixCondition{},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			secrets:  map[string]v1.Secret{},
+			expected: nil,
+			err:      errors.New("either a pod or a probe condition must be specified"),
+		},
+		{
+			name: "Same neighbor disabled in one configuration",
+			fromK8s: []v1beta1.FRRConfiguration{
+				{
+					Spec: v1beta1.FRRConfigurationSpec{
+						BGP: v1beta1.BGPConfig{
+							Routers: []v1beta1.Router{
+								{
+									ASN: 65001,
+									Neighbors: []v1beta1.Neighbor{
+										{
+											ASN:     65002,
+											Address: "192.0.2.2",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					Spec: v1beta1.FRRConfigurationSpec{
+						BGP: v1beta1.BGPConfig{
+							Routers: []v1beta1.Router{
+								{
+									ASN: 65001,
+									Neighbors: []v1beta1.Neighbor{
+										{
+											ASN:             65002,
+											Address:         "192.0.2.2",
+											Disabled:        true,
+											DisabledMessage: "maintenance",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			secrets: map[string]v1.Secret{},
+			expected: &frr.Config{
+				Routers: []*frr.RouterConfig{
+					{
+						MyASN: 65001,
+						Neighbors: []*frr.NeighborConfig{
+							{
+								IPFamily: ipfamily.IPv4,
+								Name:     "65002@192.0.2.2",
+								ASN:      65002,
+								Addr:     "192.0.2.2",
+								Outgoing: frr.AllowedOut{
+									PrefixesV4: []frr.OutgoingFilter{},
+									PrefixesV6: []frr.OutgoingFilter{},
+								},
+								Incoming: frr.AllowedIn{
+									PrefixesV4: []frr.IncomingFilter{},
+									PrefixesV6: []frr.IncomingFilter{},
+								},
+								AlwaysBlock:     []frr.IncomingFilter{},
+								Disabled:        true,
+								DisabledMessage: "maintenance",
+							},
+						},
+						IPV4Prefixes: []string{},
+						IPV6Prefixes: []string{},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+			},
+			err: nil,
+		},
+		{
+			name: "Router advertising the node's pod cidrs",
+			fromK8s: []v1beta1.FRRConfiguration{
+				{
+					Spec: v1beta1.FRRConfigurationSpec{
+						BGP: v1beta1.BGPConfig{
+							Routers: []v1beta1.Router{
+								{
+									ASN:               65001,
+									Prefixes:          []string{"192.0.2.0/24", "10.244.1.0/24"},
+									AdvertisePodCIDRs: true,
+									Neighbors: []v1beta1.Neighbor{
+										{
+											ASN:     65002,
+											Address: "192.0.2.2",
+											ToAdvertise: v1beta1.Advertise{
+												Allowed: v1beta1.AllowedOutPrefixes{
+													Prefixes: []string{"10.244.1.0/24", "fd00:10:244:1::/64"},
+												},
+											},
+										},
+									},
+								},
+								{
+									ASN: 65001,
+									VRF: "red",
+								},
+							},
+						},
+					},
+				},
+			},
+			secrets:  map[string]v1.Secret{},
+			podCIDRs: []string{"10.244.1.0/24", "fd00:10:244:1::/64"},
+			expected: &frr.Config{
+				Routers: []*frr.RouterConfig{
+					{
+						MyASN: 65001,
+						Neighbors: []*frr.NeighborConfig{
+							{
+								IPFamily: ipfamily.IPv4,
+								Name:     "65002@192.0.2.2",
+								ASN:      65002,
+								Addr:     "192.0.2.2",
+								Outgoing: frr.AllowedOut{
+									PrefixesV4: []frr.OutgoingFilter{
+										{
+											IPFamily: ipfamily.IPv4,
+											Prefix:   "10.244.1.0/24",
+										},
+									},
+									PrefixesV6: []frr.OutgoingFilter{
+										{
+											IPFamily: ipfamily.IPv6,
+											Prefix:   "fd00:10:244:1::/64",
+										},
+									},
+								},
+								Incoming: frr.AllowedIn{
+									PrefixesV4: []frr.IncomingFilter{},
+									PrefixesV6: []frr.IncomingFilter{},
+								},
+								AlwaysBlock: []frr.IncomingFilter{},
+							},
+						},
+						IPV4Prefixes: []string{"192.0.2.0/24", "10.244.1.0/24"},
+						IPV6Prefixes: []string{"fd00:10:244:1::/64"},
+					},
+					{
+						MyASN:        65001,
+						VRF:          "red",
+						Neighbors:    []*frr.NeighborConfig{},
+						IPV4Prefixes: []string{},
+						IPV6Prefixes: []string{},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+			},
+			err: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resources := ClusterResources{
+				FRRConfigs:      test.fromK8s,
+				PasswordSecrets: test.secrets,
+				Services:        test.services,
+				PodCIDRs:        test.podCIDRs,
+				Pods:            test.pods,
+				HealthyProbes:   test.probes,
+				Defaults:        test.defaults,
+			}
+			frr, warnings, err := apiToFRR(resources, test.alwaysBlock)
+			if test.err != nil && err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			if test.err == nil && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if diff := cmp.Diff(frr, test.expected); diff != "" {
+				t.Fatalf("config different from expected: %s", diff)
+			}
+			if diff := cmp.Diff(warnings, test.warnings); diff != "" {
+				t.Fatalf("warnings different from expected: %s", diff)
 			}
 		})
 	}
@@ -2520,7 +3333,7 @@ func TestFilterForSelector(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			filter, err := filterForSelector(test.selector)
+			filter, err := filterForSelector(test.selector, field.NewPath("prefix"))
 			if test.mustFail && err == nil {
 				t.Fatalf("expecting error, got nil")
 			}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
+	"github.com/metallb/frr-k8s/internal/health"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	defaultProbeHost             = "127.0.0.1"
+	defaultProbePeriod           = 10 * time.Second
+	defaultProbeTimeout          = time.Second
+	defaultProbeFailureThreshold = 3
+)
+
+// HealthProber runs the probes the conditional prefixes depend on.
+type HealthProber interface {
+	// Update replaces the set of probes to run and returns the keys of the healthy ones.
+	Update(probes []health.Probe) sets.Set[string]
+}
+
+// conditionMet tells if the given condition is satisfied, given the pods running on
+// the node and the healthy probes.
+func conditionMet(c v1beta1.PrefixCondition, resources ClusterResources) (bool, error) {
+	if c.Pod != nil && c.Probe != nil {
+		return false, errors.New("pod and probe conditions are mutually exclusive")
+	}
+	if c.Pod != nil {
+		return podConditionMet(*c.Pod, resources.Pods)
+	}
+	if c.Probe != nil {
+		probe, err := probeForCondition(*c.Probe)
+		if err != nil {
+			return false, err
+		}
+		return resources.HealthyProbes.Has(probe.Key()), nil
+	}
+	return false, errors.New("either a pod or a probe condition must be specified")
+}
+
+func podConditionMet(c v1beta1.PodCondition, pods []corev1.Pod) (bool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&c.Selector)
+	if err != nil {
+		return false, fmt.Errorf("invalid pod selector: %w", err)
+	}
+	for _, p := range pods {
+		if c.Namespace != "" && p.Namespace != c.Namespace {
+			continue
+		}
+		if !selector.Matches(labels.Set(p.Labels)) {
+			continue
+		}
+		if podIsReady(p) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func podIsReady(p corev1.Pod) bool {
+	if p.DeletionTimestamp != nil {
+		return false
+	}
+	for _, c := range p.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func probeForCondition(c v1beta1.ProbeCondition) (health.Probe, error) {
+	res := health.Probe{
+		Host:             defaultProbeHost,
+		Period:           defaultProbePeriod,
+		Timeout:          defaultProbeTimeout,
+		FailureThreshold: defaultProbeFailureThreshold,
+	}
+	if c.PeriodSeconds > 0 {
+		res.Period = time.Duration(c.PeriodSeconds) * time.Second
+	}
+	if c.TimeoutSeconds > 0 {
+		res.Timeout = time.Duration(c.TimeoutSeconds) * time.Second
+	}
+	if c.FailureThreshold > 0 {
+		res.FailureThreshold = int(c.FailureThreshold)
+	}
+
+	switch {
+	case c.HTTPGet != nil && c.TCPSocket != nil:
+		return health.Probe{}, errors.New("httpGet and tcpSocket probes are mutually exclusive")
+	case c.HTTPGet != nil:
+		res.Type = health.HTTPProbe
+		res.Port = c.HTTPGet.Port
+		res.Path = c.HTTPGet.Path
+		if c.HTTPGet.Host != "" {
+			res.Host = c.HTTPGet.Host
+		}
+	case c.TCPSocket != nil:
+		res.Type = health.TCPProbe
+		res.Port = c.TCPSocket.Port
+		if c.TCPSocket.Host != "" {
+			res.Host = c.TCPSocket.Host
+		}
+	default:
+		return health.Probe{}, errors.New("either an httpGet or a tcpSocket probe must be specified")
+	}
+	return res, nil
+}
+
+// probesForConfigs returns the probes required by the conditional prefixes of the given configurations.
+// Invalid probes are skipped, as they are reported when converting the configurations.
+func probesForConfigs(cfgs []v1beta1.FRRConfiguration) []health.Probe {
+	res := []health.Probe{}
+	for _, cfg := range cfgs {
+		for _, r := range cfg.Spec.BGP.Routers {
+			for _, p := range r.ConditionalPrefixes {
+				if p.Condition.Probe == nil {
+					continue
+				}
+				probe, err := probeForCondition(*p.Condition.Probe)
+				if err != nil {
+					continue
+				}
+				res = append(res, probe)
+			}
+		}
+	}
+	return res
+}
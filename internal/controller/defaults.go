@@ -0,0 +1,136 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
+	"github.com/metallb/frr-k8s/internal/frr"
+	"github.com/metallb/frr-k8s/internal/logging"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	defaultBGPPort       = 179
+	defaultHoldTime      = 180
+	defaultKeepaliveTime = 60
+	defaultConnectTime   = 60
+)
+
+// neighborDefaults are the values applied to the neighbors not setting them.
+type neighborDefaults struct {
+	port          uint16
+	holdTime      uint64
+	keepaliveTime uint64
+	connectTime   uint64
+	bfdProfile    *frr.BFDProfile
+}
+
+// frrNeighborDefaults are FRR's own defaults, used when no FRRDefaults is provided.
+var frrNeighborDefaults = neighborDefaults{
+	port:          defaultBGPPort,
+	holdTime:      defaultHoldTime,
+	keepaliveTime: defaultKeepaliveTime,
+	connectTime:   defaultConnectTime,
+}
+
+// defaultsSpec returns the spec of the FRRDefaults instance taken into account, if any.
+func defaultsSpec(defaults []v1beta1.FRRDefaults) *v1beta1.FRRDefaultsSpec {
+	for i := range defaults {
+		if defaults[i].Name == v1beta1.FRRDefaultsName {
+			return &defaults[i].Spec
+		}
+	}
+	return nil
+}
+
+// neighborDefaultsFor returns the neighbor defaults overridden by the given FRRDefaults spec.
+func neighborDefaultsFor(spec *v1beta1.FRRDefaultsSpec) (neighborDefaults, error) {
+	res := frrNeighborDefaults
+	if spec == nil {
+		return res, nil
+	}
+
+	if spec.Port != nil {
+		res.port = *spec.Port
+	}
+	if spec.HoldTime != nil {
+		res.holdTime = uint64(spec.HoldTime.Duration / time.Second)
+		if res.holdTime != 0 && res.holdTime < 3 {
+			return neighborDefaults{}, fmt.Errorf("invalid default hold time %q: must be 0 or >=3s", spec.HoldTime)
+		}
+	}
+	if spec.KeepaliveTime != nil {
+		res.keepaliveTime = uint64(spec.KeepaliveTime.Duration / time.Second)
+	}
+	if res.keepaliveTime > res.holdTime {
+		return neighborDefaults{}, fmt.Errorf("invalid default keepaliveTime %ds, must be lower than the default holdTime %ds", res.keepaliveTime, res.holdTime)
+	}
+	if spec.ConnectTime != nil {
+		res.connectTime = uint64(spec.ConnectTime.Duration / time.Second)
+	}
+	if spec.BFDProfile != nil {
+		if err := validateProfileName(spec.BFDProfile.Name); err != nil {
+			return neighborDefaults{}, fmt.Errorf("invalid default bfd profile: %w", err)
+		}
+		res.bfdProfile = bfdProfileToFRR(*spec.BFDProfile)
+	}
+	return res, nil
+}
+
+// alwaysBlockFor returns the given cidrs with the ones blocked by the given FRRDefaults spec.
+func alwaysBlockFor(spec *v1beta1.FRRDefaultsSpec, alwaysBlock []net.IPNet) ([]net.IPNet, error) {
+	if spec == nil || len(spec.AlwaysBlock) == 0 {
+		return alwaysBlock, nil
+	}
+
+	res := make([]net.IPNet, 0, len(alwaysBlock)+len(spec.AlwaysBlock))
+	res = append(res, alwaysBlock...)
+	for _, c := range spec.AlwaysBlock {
+		_, cidr, err := net.ParseCIDR(string(c))
+		if err != nil {
+			return nil, fmt.Errorf("invalid default always block cidr %s: %w", c, err)
+		}
+		res = append(res, *cidr)
+	}
+	return res, nil
+}
+
+// logLevelFor returns the FRR log level set by the given FRRDefaults spec, if any.
+func logLevelFor(spec *v1beta1.FRRDefaultsSpec) string {
+	if spec == nil || spec.LogLevel == "" {
+		return ""
+	}
+	return frr.LogLevelToFRR(logging.Level(spec.LogLevel))
+}
+
+// applyNeighborDefaults explicitly sets the fields not set on the given neighbor,
+// when the default value is different from FRR's own default.
+func applyNeighborDefaults(neigh *frr.NeighborConfig, defaults neighborDefaults) {
+	if neigh.Port == nil && defaults.port != frrNeighborDefaults.port {
+		neigh.Port = ptr.To(defaults.port)
+	}
+	if neigh.HoldTime == nil && neigh.KeepaliveTime == nil &&
+		(defaults.holdTime != frrNeighborDefaults.holdTime || defaults.keepaliveTime != frrNeighborDefaults.keepaliveTime) {
+		neigh.HoldTime = ptr.To(defaults.holdTime)
+		neigh.KeepaliveTime = ptr.To(defaults.keepaliveTime)
+	}
+	if neigh.ConnectTime == nil && defaults.connectTime != frrNeighborDefaults.connectTime {
+		neigh.ConnectTime = ptr.To(defaults.connectTime)
+	}
+	if neigh.BFDProfile == "" && defaults.bfdProfile != nil {
+		neigh.BFDProfile = defaults.bfdProfile.Name
+	}
+}
+
+// bfdProfileOrDefault returns the name of the bfd profile used by a neighbor
+// referencing the given profile.
+func bfdProfileOrDefault(profile string, defaults neighborDefaults) string {
+	if profile == "" && defaults.bfdProfile != nil {
+		return defaults.bfdProfile.Name
+	}
+	return profile
+}
@@ -0,0 +1,16 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DrainAnnotation is the annotation that marks a node as being drained,
+// in addition to the node being unschedulable.
+const DrainAnnotation = "frrk8s.metallb.io/drain"
+
+// nodeIsDraining tells if the given node is cordoned or annotated as being drained.
+func nodeIsDraining(node *corev1.Node) bool {
+	return node.Spec.Unschedulable || node.Annotations[DrainAnnotation] == "true"
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeIsDraining(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     v1.Node
+		expected bool
+	}{
+		{
+			name:     "schedulable",
+			node:     v1.Node{},
+			expected: false,
+		},
+		{
+			name:     "cordoned",
+			node:     v1.Node{Spec: v1.NodeSpec{Unschedulable: true}},
+			expected: true,
+		},
+		{
+			name:     "annotated",
+			node:     v1.Node{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{DrainAnnotation: "true"}}},
+			expected: true,
+		},
+		{
+			name:     "annotated with false",
+			node:     v1.Node{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{DrainAnnotation: "false"}}},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if res := nodeIsDraining(&test.node); res != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ErrorReason classifies the errors found when converting the configurations.
+type ErrorReason string
+
+const (
+	// ReasonInvalid is a field with an invalid value.
+	ReasonInvalid ErrorReason = "Invalid"
+	// ReasonDuplicate is an item defined more than once in the same configuration.
+	ReasonDuplicate ErrorReason = "Duplicate"
+	// ReasonNotFound is a reference to a resource that does not exist, such as a secret or a bfd profile.
+	ReasonNotFound ErrorReason = "NotFound"
+	// ReasonConflict is a field set to different values by multiple configurations.
+	ReasonConflict ErrorReason = "Conflict"
+	// ReasonForbidden is a field whose usage is not allowed by the cluster policies.
+	ReasonForbidden ErrorReason = "Forbidden"
+	// ReasonUnknown is any other error.
+	ReasonUnknown ErrorReason = "Unknown"
+)
+
+// TransientError is an error that happens due to interdependencies
+// between crds, such as referencing non-existing secrets.
+// Since we don't want webhooks to make assumptions on ordering, we reset the
+// fields that could cause a transient error from configurations before validating them.
+type TransientError struct {
+	Message string
+}
+
+func (e TransientError) Error() string { return e.Message }
+
+// ConversionError is an error found when converting a given field of a configuration.
+type ConversionError struct {
+	Reason ErrorReason
+	// Object is the configuration the field belongs to.
+	Object types.NamespacedName
+	// Field is the path of the field within the object.
+	Field *field.Path
+	Err   error
+}
+
+func (e *ConversionError) Error() string {
+	msg := e.Err.Error()
+	if e.Field != nil {
+		msg = fmt.Sprintf("%s: %s", e.Field, msg)
+	}
+	if e.Object.Name != "" {
+		msg = fmt.Sprintf("%s %s", e.Object, msg)
+	}
+	return msg
+}
+
+func (e *ConversionError) Unwrap() error { return e.Err }
+
+// FieldErrorFor returns the error as a field error if it belongs to the given object, nil otherwise.
+func (e *ConversionError) FieldErrorFor(object types.NamespacedName) *field.Error {
+	if e.Object != object || e.Field == nil {
+		return nil
+	}
+	switch e.Reason {
+	case ReasonNotFound:
+		return field.NotFound(e.Field, e.Err.Error())
+	case ReasonDuplicate:
+		return field.Duplicate(e.Field, e.Err.Error())
+	case ReasonConflict, ReasonForbidden:
+		return field.Forbidden(e.Field, e.Err.Error())
+	}
+	return field.Invalid(e.Field, field.OmitValueType{}, e.Err.Error())
+}
+
+func newConversionError(reason ErrorReason, path *field.Path, format string, args ...interface{}) *ConversionError {
+	return &ConversionError{Reason: reason, Field: path, Err: fmt.Errorf(format, args...)}
+}
+
+// invalidField returns an error for the field with the given path.
+func invalidField(path *field.Path, format string, args ...interface{}) *ConversionError {
+	return newConversionError(ReasonInvalid, path, format, args...)
+}
+
+// atField returns the given error, setting the given path to it if
+// it's not already related to a field.
+func atField(path *field.Path, err error) error {
+	var convErr *ConversionError
+	if errors.As(err, &convErr) {
+		return err
+	}
+	return &ConversionError{Reason: ReasonInvalid, Field: path, Err: err}
+}
+
+// withContext prefixes the message of the given error with the given context,
+// keeping the field and the reason of the error if it's a conversion error.
+func withContext(err error, format string, args ...interface{}) error {
+	context := fmt.Sprintf(format, args...)
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		return fmt.Errorf("%s: %w", context, err)
+	}
+	res := *convErr
+	res.Err = fmt.Errorf("%s: %w", context, convErr.Err)
+	return &res
+}
+
+// inObject returns the given error, relating it to the given object
+// if it's not already related to one.
+func inObject(object types.NamespacedName, err error) error {
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		return &ConversionError{Reason: ReasonUnknown, Object: object, Err: err}
+	}
+	if convErr.Object.Name == "" {
+		convErr.Object = object
+	}
+	return err
+}
+
+// ReasonFor returns the reason of the given conversion error.
+func ReasonFor(err error) ErrorReason {
+	var convErr *ConversionError
+	if errors.As(err, &convErr) {
+		return convErr.Reason
+	}
+	var conflict *conflictError
+	if errors.As(err, &conflict) {
+		return ReasonConflict
+	}
+	var transient TransientError
+	if errors.As(err, &transient) {
+		return ReasonNotFound
+	}
+	return ReasonUnknown
+}
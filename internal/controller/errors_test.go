@@ -0,0 +1,406 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestConversionErrors(t *testing.T) {
+	configWithRouter := func(r v1beta1.Router) v1beta1.FRRConfiguration {
+		return v1beta1.FRRConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "default",
+			},
+			Spec: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{r},
+				},
+			},
+		}
+	}
+	withRaw := func(cfg v1beta1.FRRConfiguration, raw string) v1beta1.FRRConfiguration {
+		cfg.Spec.Raw.Config = raw
+		return cfg
+	}
+	object := types.NamespacedName{Namespace: "default", Name: "test"}
+
+	tests := []struct {
+		name           string
+		resources      ClusterResources
+		expectedReason ErrorReason
+		expectedField  string
+		expectedType   field.ErrorType
+	}{
+		{
+			name: "invalid prefix",
+			resources: ClusterResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					configWithRouter(v1beta1.Router{
+						ASN:      65001,
+						Prefixes: []string{"foo"},
+					}),
+				},
+			},
+			expectedReason: ReasonInvalid,
+			expectedField:  "spec.bgp.routers[0].prefixes",
+			expectedType:   field.ErrorTypeInvalid,
+		},
+		{
+			name: "router without asn",
+			resources: ClusterResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					configWithRouter(v1beta1.Router{
+						Prefixes: []string{"192.0.2.0/24"},
+					}),
+				},
+			},
+			expectedReason: ReasonInvalid,
+			expectedField:  "spec.bgp.routers[0].asn",
+			expectedType:   field.ErrorTypeInvalid,
+		},
+		{
+			name: "neighbor without asn",
+			resources: ClusterResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					configWithRouter(v1beta1.Router{
+						ASN: 65001,
+						Neighbors: []v1beta1.Neighbor{
+							{
+								Address: "192.0.2.1",
+							},
+						},
+					}),
+				},
+			},
+			expectedReason: ReasonInvalid,
+			expectedField:  "spec.bgp.routers[0].neighbors[0].asn",
+			expectedType:   field.ErrorTypeInvalid,
+		},
+		{
+			name: "disabled message with a newline",
+			resources: ClusterResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					configWithRouter(v1beta1.Router{
+						ASN: 65001,
+						Neighbors: []v1beta1.Neighbor{
+							{
+								ASN:             65002,
+								Address:         "192.0.2.1",
+								Disabled:        true,
+								DisabledMessage: "maintenance\nroute-map foo permit 10",
+							},
+						},
+					}),
+				},
+			},
+			expectedReason: ReasonInvalid,
+			expectedField:  "spec.bgp.routers[0].neighbors[0].disabledMessage",
+			expectedType:   field.ErrorTypeInvalid,
+		},
+		{
+			name: "invalid timers",
+			resources: ClusterResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					configWithRouter(v1beta1.Router{
+						ASN: 65001,
+						Neighbors: []v1beta1.Neighbor{
+							{
+								ASN:      65002,
+								Address:  "192.0.2.1",
+								HoldTime: &metav1.Duration{Duration: 1},
+							},
+						},
+					}),
+				},
+			},
+			expectedReason: ReasonInvalid,
+			expectedField:  "spec.bgp.routers[0].neighbors[0].keepaliveTime",
+			expectedType:   field.ErrorTypeInvalid,
+		},
+		{
+			name: "invalid incoming prefix",
+			resources: ClusterResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					configWithRouter(v1beta1.Router{
+						ASN: 65001,
+						Neighbors: []v1beta1.Neighbor{
+							{
+								ASN:     65002,
+								Address: "192.0.2.1",
+								ToReceive: v1beta1.Receive{
+									Allowed: v1beta1.AllowedInPrefixes{
+										Prefixes: []v1beta1.PrefixSelector{{Prefix: "foo"}},
+									},
+								},
+							},
+						},
+					}),
+				},
+			},
+			expectedReason: ReasonInvalid,
+			expectedField:  "spec.bgp.routers[0].neighbors[0].toReceive.allowed.prefixes[0].prefix",
+			expectedType:   field.ErrorTypeInvalid,
+		},
+		{
+			name: "missing secret",
+			resources: ClusterResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					configWithRouter(v1beta1.Router{
+						ASN: 65001,
+						Neighbors: []v1beta1.Neighbor{
+							{
+								ASN:            65002,
+								Address:        "192.0.2.1",
+								PasswordSecret: v1.SecretReference{Name: "missing"},
+							},
+						},
+					}),
+				},
+			},
+			expectedReason: ReasonNotFound,
+			expectedField:  "spec.bgp.routers[0].neighbors[0].passwordSecret",
+			expectedType:   field.ErrorTypeNotFound,
+		},
+		{
+			name: "missing bfd profile",
+			resources: ClusterResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					configWithRouter(v1beta1.Router{
+						ASN: 65001,
+						Neighbors: []v1beta1.Neighbor{
+							{
+								ASN:        65002,
+								Address:    "192.0.2.1",
+								BFDProfile: "missing",
+							},
+						},
+					}),
+				},
+			},
+			expectedReason: ReasonNotFound,
+			expectedField:  "spec.bgp.routers[0].neighbors[0].bfdProfile",
+			expectedType:   field.ErrorTypeNotFound,
+		},
+		{
+			name: "router id not an ip",
+			resources: ClusterResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					configWithRouter(v1beta1.Router{
+						ASN: 65001,
+						ID:  "1.2.3.4\nip route 0.0.0.0/0 192.0.2.1",
+					}),
+				},
+			},
+			expectedReason: ReasonInvalid,
+			expectedField:  "spec.bgp.routers[0].id",
+			expectedType:   field.ErrorTypeInvalid,
+		},
+		{
+			name: "vrf not an interface name",
+			resources: ClusterResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					configWithRouter(v1beta1.Router{
+						ASN: 65001,
+						VRF: "red\nexit",
+					}),
+				},
+			},
+			expectedReason: ReasonInvalid,
+			expectedField:  "spec.bgp.routers[0].vrf",
+			expectedType:   field.ErrorTypeInvalid,
+		},
+		{
+			name: "password with a newline",
+			resources: ClusterResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					configWithRouter(v1beta1.Router{
+						ASN: 65001,
+						Neighbors: []v1beta1.Neighbor{
+							{
+								ASN:      65002,
+								Address:  "192.0.2.1",
+								Password: "secret\nexit",
+							},
+						},
+					}),
+				},
+			},
+			expectedReason: ReasonInvalid,
+			expectedField:  "spec.bgp.routers[0].neighbors[0].password",
+			expectedType:   field.ErrorTypeInvalid,
+		},
+		{
+			name: "bfd profile name with a space",
+			resources: ClusterResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					func() v1beta1.FRRConfiguration {
+						cfg := configWithRouter(v1beta1.Router{ASN: 65001})
+						cfg.Spec.BGP.BFDProfiles = []v1beta1.BFDProfile{{Name: "foo\n  exit"}}
+						return cfg
+					}(),
+				},
+			},
+			expectedReason: ReasonInvalid,
+			expectedField:  "spec.bgp.bfdProfiles[0].name",
+			expectedType:   field.ErrorTypeInvalid,
+		},
+		{
+			name: "raw config redefining a generated route-map",
+			resources: ClusterResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					withRaw(configWithRouter(v1beta1.Router{
+						ASN: 65001,
+						Neighbors: []v1beta1.Neighbor{
+							{
+								ASN:     65002,
+								Address: "192.0.2.1",
+							},
+						},
+					}), "route-map 192.0.2.1-out permit 1\n  set metric 100"),
+				},
+			},
+			expectedReason: ReasonConflict,
+			expectedField:  "spec.raw.rawConfig",
+			expectedType:   field.ErrorTypeForbidden,
+		},
+		{
+			name: "scoped raw config redefining a generated prefix-list",
+			resources: ClusterResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					func() v1beta1.FRRConfiguration {
+						cfg := configWithRouter(v1beta1.Router{
+							ASN: 65001,
+							Neighbors: []v1beta1.Neighbor{
+								{
+									ASN:     65002,
+									Address: "192.0.2.1",
+								},
+							},
+						})
+						cfg.Spec.Raw.Scoped = []v1beta1.ScopedRawConfig{
+							{ASN: 65001, Config: "bgp bestpath as-path multipath-relax"},
+							{ASN: 65001, Config: "ip prefix-list 192.0.2.1-pl-ipv4 seq 100 permit 0.0.0.0/0"},
+						}
+						return cfg
+					}(),
+				},
+			},
+			expectedReason: ReasonConflict,
+			expectedField:  "spec.raw.scoped[1].rawConfig",
+			expectedType:   field.ErrorTypeForbidden,
+		},
+		{
+			name: "conflicting routers",
+			resources: ClusterResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					configWithRouter(v1beta1.Router{
+						ASN: 65001,
+					}),
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "other",
+							Namespace: "default",
+						},
+						Spec: v1beta1.FRRConfigurationSpec{
+							BGP: v1beta1.BGPConfig{
+								Routers: []v1beta1.Router{{ASN: 65002}},
+							},
+						},
+					},
+				},
+			},
+			expectedReason: ReasonConflict,
+			expectedField:  "spec.bgp.routers[0].asn",
+			expectedType:   field.ErrorTypeForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := apiToFRR(test.resources, []net.IPNet{})
+			if err == nil {
+				t.Fatalf("expecting error, got nil")
+			}
+			if reason := ReasonFor(err); reason != test.expectedReason {
+				t.Fatalf("expecting reason %s, got %s (%s)", test.expectedReason, reason, err)
+			}
+
+			fe, ok := err.(interface {
+				FieldErrorFor(types.NamespacedName) *field.Error
+			})
+			if !ok {
+				t.Fatalf("expecting a field related error, got %T (%s)", err, err)
+			}
+			fieldErr := fe.FieldErrorFor(object)
+			if fieldErr == nil {
+				t.Fatalf("expecting a field error for %s, got nil (%s)", object, err)
+			}
+			if diff := cmp.Diff(test.expectedField, fieldErr.Field); diff != "" {
+				t.Fatalf("field different from expected: %s", diff)
+			}
+			if fieldErr.Type != test.expectedType {
+				t.Fatalf("expecting error type %s, got %s", test.expectedType, fieldErr.Type)
+			}
+
+			if fe.FieldErrorFor(types.NamespacedName{Namespace: "default", Name: "unrelated"}) != nil {
+				t.Fatalf("expecting no field error for an unrelated object")
+			}
+		})
+	}
+}
+
+func TestNeighborErrorContext(t *testing.T) {
+	cfg := v1beta1.FRRConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FRRConfigurationSpec{
+			BGP: v1beta1.BGPConfig{
+				Routers: []v1beta1.Router{
+					{
+						ASN: 65001,
+						VRF: "red",
+						Neighbors: []v1beta1.Neighbor{
+							{
+								ASN:     65002,
+								Address: "192.0.2.1",
+								ToReceive: v1beta1.Receive{
+									Allowed: v1beta1.AllowedInPrefixes{
+										Prefixes: []v1beta1.PrefixSelector{{Prefix: "foo"}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	_, _, err := apiToFRR(ClusterResources{FRRConfigs: []v1beta1.FRRConfiguration{cfg}}, []net.IPNet{})
+	if err == nil {
+		t.Fatalf("expecting error, got nil")
+	}
+	fieldErr := err.(*ConversionError).FieldErrorFor(types.NamespacedName{Namespace: "default", Name: "test"})
+	if fieldErr == nil {
+		t.Fatalf("expecting a field error, got nil (%s)", err)
+	}
+	if fieldErr.Field != "spec.bgp.routers[0].neighbors[0].toReceive.allowed.prefixes[0].prefix" {
+		t.Fatalf("unexpected field %s", fieldErr.Field)
+	}
+	if !strings.Contains(fieldErr.Detail, "failed to process neighbor 65002@192.0.2.1 for router 65001-red") {
+		t.Fatalf("expecting the neighbor and the router in the error, got %q", fieldErr.Detail)
+	}
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"unicode"
+
+	"github.com/metallb/frr-k8s/internal/community"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// The free form fields end up in the rendered configuration as they are, so they are validated
+// before rendering it to prevent them from injecting FRR commands (i.e. via a newline).
+
+// maxVRFNameLength is the maximum length of the name of a network interface.
+const maxVRFNameLength = 15
+
+var vrfNameRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// validateRouterID checks that the given router id, if set, is an ip address.
+func validateRouterID(id string) error {
+	if id == "" {
+		return nil
+	}
+	if net.ParseIP(id) == nil {
+		return fmt.Errorf("router id %q is not an ip address", id)
+	}
+	return nil
+}
+
+// validateVRFName checks that the given vrf name, if set, is a valid interface name.
+func validateVRFName(vrf string) error {
+	if vrf == "" {
+		return nil
+	}
+	if len(vrf) > maxVRFNameLength || !vrfNameRegex.MatchString(vrf) {
+		return fmt.Errorf("vrf %q is not a valid interface name, it must be at most %d characters among letters, digits, '_', '.' and '-'", vrf, maxVRFNameLength)
+	}
+	return nil
+}
+
+func validateIP(address string) error {
+	if net.ParseIP(address) == nil {
+		return fmt.Errorf("%q is not an ip address", address)
+	}
+	return nil
+}
+
+func validateCIDR(cidr string) error {
+	if _, _, err := net.ParseCIDR(cidr); err != nil {
+		return fmt.Errorf("%q is not a cidr", cidr)
+	}
+	return nil
+}
+
+// validateHost checks that the given host is either an ip address or a dns name.
+func validateHost(host string) error {
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+		return fmt.Errorf("%q is neither an ip address nor a dns name", host)
+	}
+	return nil
+}
+
+func validateCommunity(c string) error {
+	_, err := community.New(c)
+	return err
+}
+
+// validateProfileName checks that the given bfd profile name is a single word.
+func validateProfileName(name string) error {
+	for _, r := range name {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return fmt.Errorf("bfd profile name %q must not contain spaces nor control characters", name)
+		}
+	}
+	return nil
+}
+
+// validatePassword checks that the given password does not contain any control character.
+// The password is not part of the error, as it is reported in the status.
+func validatePassword(password string) error {
+	for _, r := range password {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("the password must not contain control characters")
+		}
+	}
+	return nil
+}
+
+// validateSingleLine checks that the given value does not contain any control
+// character, such as a newline.
+func validateSingleLine(value string) error {
+	for _, r := range value {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%q contains the control character %U", value, r)
+		}
+	}
+	return nil
+}
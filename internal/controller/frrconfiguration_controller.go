@@ -18,27 +18,33 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"reflect"
 	"sync"
 
 	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	frrk8sv1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
 	"github.com/metallb/frr-k8s/internal/frr"
+	"github.com/metallb/frr-k8s/internal/health"
 )
 
 const ConversionSuccess = "success"
@@ -53,8 +59,20 @@ type FRRConfigurationReconciler struct {
 	Namespace          string
 	ReloadStatus       func()
 	conversionResult   string
+	ignoredConfigs     []string
 	conversionResMutex sync.Mutex
 	AlwaysBlockCIDRS   []net.IPNet
+	// AdvertiseServices enables watching the services and their endpoints, in order
+	// to advertise the IPs of the services selected by the routers' ServiceSelector.
+	AdvertiseServices bool
+	// Prober runs the probes the conditional prefixes depend on. When not set,
+	// the prefixes depending on a probe are never advertised.
+	Prober HealthProber
+	// HealthUpdate notifies a change in the result of the probes.
+	HealthUpdate chan event.GenericEvent
+	// DrainMode is how the advertised routes are handled when the node
+	// is being drained.
+	DrainMode frr.DrainMode
 }
 
 func (r *FRRConfigurationReconciler) ConversionResult() string {
@@ -63,10 +81,23 @@ func (r *FRRConfigurationReconciler) ConversionResult() string {
 	return r.conversionResult
 }
 
+// IgnoredConfigurations returns the configurations selecting the node that were ignored
+// by the last reconciliation because not allowed by the cluster policy.
+func (r *FRRConfigurationReconciler) IgnoredConfigurations() []string {
+	r.conversionResMutex.Lock()
+	defer r.conversionResMutex.Unlock()
+	return r.ignoredConfigs
+}
+
 // +kubebuilder:rbac:groups=frrk8s.metallb.io,resources=frrconfigurations,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=frrk8s.metallb.io,resources=frrconfigurations/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=frrk8s.metallb.io,resources=frrconfigurations/finalizers,verbs=update
+// +kubebuilder:rbac:groups=frrk8s.metallb.io,resources=frrdefaults,verbs=get;list;watch
+// +kubebuilder:rbac:groups=frrk8s.metallb.io,resources=frrpolicies,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="discovery.k8s.io",resources=endpointslices,verbs=get;list;watch
 // +kubebuilder:rbac:groups="admissionregistration.k8s.io",resources=validatingwebhookconfigurations,verbs=get;list;watch
 // +kubebuilder:rbac:groups="admissionregistration.k8s.io",resources=validatingwebhookconfigurations,resourceNames="frr-k8s-validating-webhook-configuration",verbs=update
 
@@ -75,12 +106,15 @@ func (r *FRRConfigurationReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	defer level.Info(r.Logger).Log("controller", "FRRConfigurationReconciler", "end reconcile", req.NamespacedName.String())
 	lastConversionResult := r.conversionResult
 	conversionResult := ConversionSuccess
+	lastIgnoredConfigs := r.ignoredConfigs
+	var ignoredConfigs []string
 
 	defer func() {
 		r.conversionResMutex.Lock()
 		r.conversionResult = conversionResult
+		r.ignoredConfigs = ignoredConfigs
 		r.conversionResMutex.Unlock()
-		if conversionResult != lastConversionResult {
+		if conversionResult != lastConversionResult || !reflect.DeepEqual(ignoredConfigs, lastIgnoredConfigs) {
 			r.ReloadStatus()
 		}
 	}()
@@ -97,7 +131,13 @@ func (r *FRRConfigurationReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	level.Debug(r.Logger).Log("controller", "FRRConfigurationReconciler", "k8s config", dumpK8sConfigs(configs))
 
 	if len(configs.Items) == 0 {
-		err := r.applyEmptyConfig(req)
+		r.updateProbes(nil)
+		defaults, err := r.getDefaults(ctx)
+		if err != nil {
+			conversionResult = fmt.Sprintf("failed: %v", err)
+			return ctrl.Result{}, err
+		}
+		err = r.applyEmptyConfig(req, defaults)
 		if err != nil {
 			updateErrors.Inc()
 			configStale.Set(1)
@@ -113,10 +153,14 @@ func (r *FRRConfigurationReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, err
 	}
 
-	cfgs, err := configsForNode(configs.Items, thisNode.Labels)
+	defaults, err := r.getDefaults(ctx)
+	if err != nil {
+		conversionResult = fmt.Sprintf("failed: %v", err)
+		return ctrl.Result{}, err
+	}
+
+	policy, err := r.getPolicy(ctx)
 	if err != nil {
-		updateErrors.Inc()
-		configStale.Set(1)
 		conversionResult = fmt.Sprintf("failed: %v", err)
 		return ctrl.Result{}, err
 	}
@@ -127,22 +171,63 @@ func (r *FRRConfigurationReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, err
 	}
 
-	resources := ClusterResources{
-		FRRConfigs:      cfgs,
-		PasswordSecrets: secrets,
+	services, err := r.getServices(ctx)
+	if err != nil {
+		conversionResult = fmt.Sprintf("failed: %v", err)
+		return ctrl.Result{}, err
 	}
-	config, err := apiToFRR(resources, r.AlwaysBlockCIDRS)
+
+	pods, err := r.getPods(ctx)
 	if err != nil {
+		conversionResult = fmt.Sprintf("failed: %v", err)
+		return ctrl.Result{}, err
+	}
+
+	nodeConfig, err := buildNodeConfig(nodeConfigInput{
+		configs: configs.Items,
+		node:    thisNode,
+		resources: ClusterResources{
+			PasswordSecrets: secrets,
+			Services:        services,
+			Pods:            pods,
+			Defaults:        defaults,
+			Policy:          policy,
+		},
+		healthyProbes: func(cfgs []frrk8sv1beta1.FRRConfiguration) sets.Set[string] {
+			return r.updateProbes(probesForConfigs(cfgs))
+		},
+		alwaysBlock: r.AlwaysBlockCIDRS,
+		drainMode:   r.DrainMode,
+	})
+	ignoredConfigs = nodeConfig.ignored
+	for _, ignored := range ignoredConfigs {
+		level.Warn(r.Logger).Log("controller", "FRRConfigurationReconciler", "configuration not allowed by the cluster policy", ignored)
+	}
+	var selectorErr nodeSelectorError
+	if errors.As(err, &selectorErr) {
 		updateErrors.Inc()
 		configStale.Set(1)
-		level.Error(r.Logger).Log("controller", "FRRConfigurationReconciler", "failed to apply the config", req.NamespacedName.String(), "error", err)
 		conversionResult = fmt.Sprintf("failed: %v", err)
+		return ctrl.Result{}, err
+	}
+	if err != nil {
+		updateErrors.Inc()
+		configStale.Set(1)
+		level.Error(r.Logger).Log("controller", "FRRConfigurationReconciler", "failed to apply the config", req.NamespacedName.String(), "error", err, "reason", ReasonFor(err))
+		conversionResult = conversionFailed(err)
 		return ctrl.Result{}, nil
 	}
 
-	level.Debug(r.Logger).Log("controller", "FRRConfigurationReconciler", "frr config", dumpFRRConfig(config))
+	for _, w := range nodeConfig.warnings {
+		level.Warn(r.Logger).Log("controller", "FRRConfigurationReconciler", "conflict resolved by priority", w)
+	}
+	if nodeConfig.draining {
+		level.Info(r.Logger).Log("controller", "FRRConfigurationReconciler", "node is draining", r.NodeName, "mode", r.DrainMode)
+	}
 
-	if err := r.FRRHandler.ApplyConfig(config); err != nil {
+	level.Debug(r.Logger).Log("controller", "FRRConfigurationReconciler", "frr config", dumpFRRConfig(nodeConfig.config))
+
+	if err := r.FRRHandler.ApplyConfig(nodeConfig.config); err != nil {
 		updateErrors.Inc()
 		configStale.Set(1)
 		conversionResult = fmt.Sprintf("failed: %v", err)
@@ -156,11 +241,12 @@ func (r *FRRConfigurationReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	return ctrl.Result{}, nil
 }
 
-func (r *FRRConfigurationReconciler) applyEmptyConfig(req ctrl.Request) error {
-	config, err := apiToFRR(ClusterResources{}, []net.IPNet{})
+func (r *FRRConfigurationReconciler) applyEmptyConfig(req ctrl.Request, defaults *frrk8sv1beta1.FRRDefaultsSpec) error {
+	config, _, err := apiToFRR(ClusterResources{Defaults: defaults}, r.AlwaysBlockCIDRS)
 	if err != nil {
+		// Only invalid defaults can make the empty config fail.
 		level.Error(r.Logger).Log("controller", "FRRConfigurationReconciler", "failed to translate the empty config", req.NamespacedName.String(), "error", err)
-		panic("failed to translate empty config")
+		return err
 	}
 
 	if err := r.FRRHandler.ApplyConfig(config); err != nil {
@@ -191,19 +277,40 @@ func configsForNode(cfgs []frrk8sv1beta1.FRRConfiguration, nodeLabels map[string
 	return valid, nil
 }
 
+// conversionFailed accounts for the given conversion error and returns
+// the conversion result to be exposed in the status.
+func conversionFailed(err error) string {
+	reason := ReasonFor(err)
+	conversionErrors.WithLabelValues(string(reason)).Inc()
+	return fmt.Sprintf("failed: %v (reason: %s)", err, reason)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *FRRConfigurationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	p := predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
-			return filterNodeEvent(e, r.NodeName)
+			return filterNodeEvent(e, r.NodeName) && filterPodEvent(e, r.NodeName)
 		},
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&frrk8sv1beta1.FRRConfiguration{}).
+		Watches(&frrk8sv1beta1.FRRDefaults{}, &handler.EnqueueRequestForObject{}).
+		Watches(&frrk8sv1beta1.FRRPolicy{}, &handler.EnqueueRequestForObject{}).
 		Watches(&corev1.Node{}, &handler.EnqueueRequestForObject{}).
 		Watches(&corev1.Secret{}, &handler.EnqueueRequestForObject{}).
-		WithEventFilter(p).
+		Watches(&corev1.Pod{}, &handler.EnqueueRequestForObject{})
+
+	if r.HealthUpdate != nil {
+		b = b.WatchesRawSource(&source.Channel{Source: r.HealthUpdate}, &handler.EnqueueRequestForObject{})
+	}
+
+	if r.AdvertiseServices {
+		b = b.Watches(&corev1.Service{}, &handler.EnqueueRequestForObject{}).
+			Watches(&discovery.EndpointSlice{}, &handler.EnqueueRequestForObject{})
+	}
+
+	return b.WithEventFilter(p).
 		Complete(r)
 }
 
@@ -224,6 +331,84 @@ func (r *FRRConfigurationReconciler) getSecrets(ctx context.Context) (map[string
 	return secretsMap, nil
 }
 
+// getServices returns the services that can be advertised from this node,
+// or an empty list if the service advertisement is not enabled.
+func (r *FRRConfigurationReconciler) getServices(ctx context.Context) ([]corev1.Service, error) {
+	if !r.AdvertiseServices {
+		return []corev1.Service{}, nil
+	}
+
+	var services corev1.ServiceList
+	err := r.List(ctx, &services)
+	if err != nil {
+		level.Error(r.Logger).Log("controller", "FRRConfigurationReconciler", "error", "failed to get services", "error", err)
+		return nil, err
+	}
+
+	var slices discovery.EndpointSliceList
+	err = r.List(ctx, &slices)
+	if err != nil {
+		level.Error(r.Logger).Log("controller", "FRRConfigurationReconciler", "error", "failed to get endpointslices", "error", err)
+		return nil, err
+	}
+
+	return servicesForNode(services.Items, slices.Items, r.NodeName), nil
+}
+
+// getPods returns the pods running on this node.
+func (r *FRRConfigurationReconciler) getPods(ctx context.Context) ([]corev1.Pod, error) {
+	var pods corev1.PodList
+	err := r.List(ctx, &pods)
+	if err != nil {
+		level.Error(r.Logger).Log("controller", "FRRConfigurationReconciler", "error", "failed to get pods", "error", err)
+		return nil, err
+	}
+
+	return podsForNode(pods.Items, r.NodeName), nil
+}
+
+// podsForNode returns the given pods running on the given node.
+func podsForNode(pods []corev1.Pod, nodeName string) []corev1.Pod {
+	res := make([]corev1.Pod, 0)
+	for _, p := range pods {
+		if p.Spec.NodeName != nodeName {
+			continue
+		}
+		res = append(res, p)
+	}
+	return res
+}
+
+// getDefaults returns the cluster wide defaults, or nil if none is set.
+func (r *FRRConfigurationReconciler) getDefaults(ctx context.Context) (*frrk8sv1beta1.FRRDefaultsSpec, error) {
+	var defaults frrk8sv1beta1.FRRDefaultsList
+	err := r.List(ctx, &defaults)
+	if err != nil {
+		level.Error(r.Logger).Log("controller", "FRRConfigurationReconciler", "error", "failed to get defaults", "error", err)
+		return nil, err
+	}
+	return defaultsSpec(defaults.Items), nil
+}
+
+// getPolicy returns the cluster wide policy, or nil if none is set.
+func (r *FRRConfigurationReconciler) getPolicy(ctx context.Context) (*frrk8sv1beta1.FRRPolicySpec, error) {
+	var policies frrk8sv1beta1.FRRPolicyList
+	err := r.List(ctx, &policies)
+	if err != nil {
+		level.Error(r.Logger).Log("controller", "FRRConfigurationReconciler", "error", "failed to get policies", "error", err)
+		return nil, err
+	}
+	return policySpec(policies.Items), nil
+}
+
+// updateProbes sets the probes to run and returns the healthy ones.
+func (r *FRRConfigurationReconciler) updateProbes(probes []health.Probe) sets.Set[string] {
+	if r.Prober == nil {
+		return sets.New[string]()
+	}
+	return r.Prober.Update(probes)
+}
+
 func filterNodeEvent(e event.UpdateEvent, thisNode string) bool {
 	newNodeObj, ok := e.ObjectNew.(*corev1.Node)
 	if !ok {
@@ -245,10 +430,59 @@ func filterNodeEvent(e event.UpdateEvent, thisNode string) bool {
 		return false
 	}
 
-	// Ignoring event if it didn't change the node's labels
-	if labels.Equals(labels.Set(oldNodeObj.Labels), labels.Set(newNodeObj.Labels)) {
+	// Ignoring event if it didn't change the node's fields used to render the configuration
+	if labels.Equals(labels.Set(oldNodeObj.Labels), labels.Set(newNodeObj.Labels)) &&
+		labels.Equals(labels.Set(oldNodeObj.Annotations), labels.Set(newNodeObj.Annotations)) &&
+		reflect.DeepEqual(oldNodeObj.Status.Addresses, newNodeObj.Status.Addresses) &&
+		oldNodeObj.Spec.Unschedulable == newNodeObj.Spec.Unschedulable &&
+		reflect.DeepEqual(podCIDRsForNode(oldNodeObj), podCIDRsForNode(newNodeObj)) {
 		return false
 	}
 
 	return true
 }
+
+func filterPodEvent(e event.UpdateEvent, thisNode string) bool {
+	newPodObj, ok := e.ObjectNew.(*corev1.Pod)
+	if !ok {
+		return true
+	}
+
+	oldPodObj, ok := e.ObjectOld.(*corev1.Pod)
+	if !ok {
+		return true
+	}
+
+	// Ignoring event if it's not for a pod running on our node
+	if newPodObj.Spec.NodeName != thisNode && oldPodObj.Spec.NodeName != thisNode {
+		return false
+	}
+
+	// Ignoring event if it didn't change the pod's readiness or labels
+	if podIsReady(*oldPodObj) == podIsReady(*newPodObj) &&
+		oldPodObj.Spec.NodeName == newPodObj.Spec.NodeName &&
+		labels.Equals(labels.Set(oldPodObj.Labels), labels.Set(newPodObj.Labels)) {
+		return false
+	}
+
+	return true
+}
+
+// NewHealthEvent returns the event used to notify a change in the result of the probes.
+func NewHealthEvent() event.GenericEvent {
+	evt := stateEvent{}
+	evt.Name = "healthUpdate"
+	evt.Namespace = "frrk8sprobes"
+	return event.GenericEvent{Object: &evt}
+}
+
+// podCIDRsForNode returns the pod cidrs assigned to the given node.
+func podCIDRsForNode(node *corev1.Node) []string {
+	if len(node.Spec.PodCIDRs) > 0 {
+		return node.Spec.PodCIDRs
+	}
+	if node.Spec.PodCIDR != "" {
+		return []string{node.Spec.PodCIDR}
+	}
+	return []string{}
+}
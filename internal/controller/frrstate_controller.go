@@ -18,10 +18,14 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"regexp"
+	"strings"
+	"time"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -41,7 +45,11 @@ import (
 
 // +kubebuilder:skip
 
-var passwordRegex = regexp.MustCompile(`password.*`)
+var (
+	passwordRegex         = regexp.MustCompile(`password.*`)
+	routerRegex           = regexp.MustCompile(`^router bgp \d+(?: vrf (\S+))?`)
+	neighborShutdownRegex = regexp.MustCompile(`^\s+neighbor (\S+) shutdown`)
+)
 
 // FRRStateReconciler reconciles the FRRStatus object.
 type FRRStateReconciler struct {
@@ -75,6 +83,7 @@ func NewStateEvent() event.GenericEvent {
 
 type ConversionResultFetcher interface {
 	ConversionResult() string
+	IgnoredConfigurations() []string
 }
 
 // +kubebuilder:rbac:groups=frrk8s.metallb.io,resources=frrnodestates,verbs=get;list;watch;create;update;patch;delete
@@ -99,9 +108,15 @@ func (r *FRRStateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	frrStatus := r.FRRStatus.GetStatus()
 
 	newStatus := frrk8sv1beta1.FRRNodeStateStatus{
-		RunningConfig:        cleanPasswords(frrStatus.Current),
-		LastReloadResult:     cleanPasswords(frrStatus.LastReloadResult),
-		LastConversionResult: r.ConversionResult.ConversionResult(),
+		RunningConfig:         cleanPasswords(frrStatus.Current),
+		LastReloadResult:      cleanPasswords(frrStatus.LastReloadResult),
+		RolledBack:            frrStatus.RolledBack,
+		ReloadHistory:         reloadHistory(frrStatus.History),
+		LastConversionResult:  r.ConversionResult.ConversionResult(),
+		DisabledNeighbors:     disabledNeighbors(frrStatus.Current),
+		IgnoredConfigurations: r.ConversionResult.IgnoredConfigurations(),
+		ConfigDrift:           cleanPasswordsInLines(frrStatus.ConfigDrift),
+		Conditions:            driftConditions(state.Status.Conditions, frrStatus),
 	}
 	if reflect.DeepEqual(state.Status, newStatus) { // Do nothing
 		return ctrl.Result{}, nil
@@ -137,6 +152,88 @@ func (r *FRRStateReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
+// driftConditions returns the given conditions with the ConfigDrift one updated
+// according to the given status.
+func driftConditions(conditions []metav1.Condition, status frr.Status) []metav1.Condition {
+	res := make([]metav1.Condition, len(conditions))
+	copy(res, conditions)
+
+	condition := metav1.Condition{
+		Type:    frrk8sv1beta1.ConditionConfigDrift,
+		Status:  metav1.ConditionUnknown,
+		Reason:  "NoRenderedConfig",
+		Message: "no configuration was rendered yet",
+	}
+	switch {
+	case status.ConfigDriftKnown && len(status.ConfigDrift) > 0:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "RunningConfigDiffers"
+		condition.Message = "the running configuration differs from the rendered one"
+	case status.ConfigDriftKnown:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "RunningConfigMatches"
+		condition.Message = "the running configuration matches the rendered one"
+	}
+	// The transition time is truncated as it is serialized, so the status can be compared with the stored one.
+	condition.LastTransitionTime = metav1.NewTime(time.Now().Truncate(time.Second))
+	meta.SetStatusCondition(&res, condition)
+	return res
+}
+
+func cleanPasswordsInLines(lines []string) []string {
+	if len(lines) == 0 {
+		return nil
+	}
+	res := make([]string, 0, len(lines))
+	for _, l := range lines {
+		res = append(res, cleanPasswords(l))
+	}
+	return res
+}
+
+// reloadHistory converts the given reload attempts to their api representation. The times
+// are truncated to the second, as they are serialized, so the status can be compared with
+// the stored one.
+func reloadHistory(history []frr.ReloadAttempt) []frrk8sv1beta1.ReloadAttempt {
+	if len(history) == 0 {
+		return nil
+	}
+	res := make([]frrk8sv1beta1.ReloadAttempt, 0, len(history))
+	for _, a := range history {
+		res = append(res, frrk8sv1beta1.ReloadAttempt{
+			Time:         metav1.NewTime(a.Time.Truncate(time.Second)),
+			Trigger:      string(a.Trigger),
+			Duration:     metav1.Duration{Duration: a.Duration},
+			Result:       cleanPasswords(a.Result),
+			LinesAdded:   a.LinesAdded,
+			LinesRemoved: a.LinesRemoved,
+		})
+	}
+	return res
+}
+
+// disabledNeighbors returns the neighbors shut down in the given running config.
+func disabledNeighbors(runningConfig string) []string {
+	var res []string
+	vrf := ""
+	for _, line := range strings.Split(runningConfig, "\n") {
+		if m := routerRegex.FindStringSubmatch(line); m != nil {
+			vrf = m[1]
+			continue
+		}
+		m := neighborShutdownRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if vrf == "" {
+			res = append(res, m[1])
+			continue
+		}
+		res = append(res, fmt.Sprintf("%s vrf %s", m[1], vrf))
+	}
+	return res
+}
+
 func cleanPasswords(toClean string) string {
 	cleaned := passwordRegex.ReplaceAllString(toClean, "password <retracted>")
 	return cleaned
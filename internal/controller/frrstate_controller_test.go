@@ -21,12 +21,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gstruct"
 
 	frrk8sv1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
 	"github.com/metallb/frr-k8s/internal/frr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
@@ -42,23 +45,32 @@ var (
 type fakeFRRStatus struct {
 	lastApplied      string
 	lastReloadResult string
+	configDrift      []string
+	configDriftKnown bool
 }
 
 func (f *fakeFRRStatus) GetStatus() frr.Status {
 	return frr.Status{
 		Current:          f.lastApplied,
 		LastReloadResult: f.lastReloadResult,
+		ConfigDrift:      f.configDrift,
+		ConfigDriftKnown: f.configDriftKnown,
 	}
 }
 
 type fakeConversionResult struct {
-	result string
+	result  string
+	ignored []string
 }
 
 func (f *fakeConversionResult) ConversionResult() string {
 	return f.result
 }
 
+func (f *fakeConversionResult) IgnoredConfigurations() []string {
+	return f.ignored
+}
+
 var _ = Describe("Frrk8s node status", func() {
 	Context("when a FRRConfiguration is created", func() {
 
@@ -171,6 +183,60 @@ var _ = Describe("Frrk8s node status", func() {
 					}),
 				}))
 		})
+
+		It("should report the config drift", func() {
+			fakeStatus.configDrift = []string{"- router bgp 65001", "+ neighbor 192.0.2.2 password supersecret"}
+			fakeStatus.configDriftKnown = true
+			defer func() {
+				fakeStatus.configDrift = nil
+				fakeStatus.configDriftKnown = false
+			}()
+
+			updateChan <- NewStateEvent()
+
+			Eventually(func() frrk8sv1beta1.FRRNodeState {
+				nodeStatusList := frrk8sv1beta1.FRRNodeStateList{}
+				err := k8sClient.List(context.Background(), &nodeStatusList)
+				Expect(err).ToNot(HaveOccurred())
+				if len(nodeStatusList.Items) != 1 {
+					return frrk8sv1beta1.FRRNodeState{}
+				}
+				return nodeStatusList.Items[0]
+			}, time.Minute, time.Second).Should(
+				gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+					"Status": gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+						"ConfigDrift": Equal([]string{"- router bgp 65001", "+ neighbor 192.0.2.2 password <retracted>"}),
+						"Conditions": ContainElement(gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+							"Type":   Equal(frrk8sv1beta1.ConditionConfigDrift),
+							"Status": Equal(metav1.ConditionTrue),
+							"Reason": Equal("RunningConfigDiffers"),
+						})),
+					}),
+				}))
+
+			fakeStatus.configDrift = nil
+			updateChan <- NewStateEvent()
+
+			Eventually(func() frrk8sv1beta1.FRRNodeState {
+				nodeStatusList := frrk8sv1beta1.FRRNodeStateList{}
+				err := k8sClient.List(context.Background(), &nodeStatusList)
+				Expect(err).ToNot(HaveOccurred())
+				if len(nodeStatusList.Items) != 1 {
+					return frrk8sv1beta1.FRRNodeState{}
+				}
+				return nodeStatusList.Items[0]
+			}, time.Minute, time.Second).Should(
+				gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+					"Status": gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+						"ConfigDrift": BeEmpty(),
+						"Conditions": ContainElement(gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+							"Type":   Equal(frrk8sv1beta1.ConditionConfigDrift),
+							"Status": Equal(metav1.ConditionFalse),
+							"Reason": Equal("RunningConfigMatches"),
+						})),
+					}),
+				}))
+		})
 	})
 })
 
@@ -181,3 +247,23 @@ func TestClean(t *testing.T) {
 		t.Fatalf("Expected foo\n password <retracted> got %s", cleaned)
 	}
 }
+
+func TestDisabledNeighbors(t *testing.T) {
+	runningConfig := `frr version 8.4
+router bgp 64512
+ neighbor 172.18.0.5 remote-as 64512
+ neighbor 172.18.0.5 shutdown message maintenance
+ neighbor 172.18.0.6 remote-as 64512
+exit
+!
+router bgp 64512 vrf red
+ neighbor 172.30.0.2 remote-as 64513
+ neighbor 172.30.0.2 shutdown
+exit
+!
+`
+	expected := []string{"172.18.0.5", "172.30.0.2 vrf red"}
+	if diff := cmp.Diff(expected, disabledNeighbors(runningConfig)); diff != "" {
+		t.Fatalf("disabled neighbors different from expected: %s", diff)
+	}
+}
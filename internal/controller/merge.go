@@ -4,33 +4,172 @@ package controller
 
 import (
 	"fmt"
+	"strings"
 
+	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
 	"github.com/metallb/frr-k8s/internal/frr"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
-const (
-	defaultBGPPort       = 179
-	defaultHoldTime      = 180
-	defaultKeepaliveTime = 60
-	defaultConnectTime   = 60
-)
+// configSource identifies the configuration, and the path within it, a merged value comes from.
+type configSource struct {
+	object   types.NamespacedName
+	path     *field.Path
+	priority int
+}
 
-// Merges two router configs.
-func mergeRouterConfigs(r, toMerge *frr.RouterConfig) (*frr.RouterConfig, error) {
-	err := routersAreCompatible(r, toMerge)
-	if err != nil {
-		return nil, err
+func (s configSource) child(name string) configSource {
+	s.path = s.path.Child(name)
+	return s
+}
+
+func (s configSource) neighbor(index int) configSource {
+	s.path = s.path.Child("neighbors").Index(index)
+	return s
+}
+
+func (s configSource) String() string {
+	return fmt.Sprintf("%s %s", s.object, s.path)
+}
+
+// sourceForRouter returns the source of the given router of the given configuration.
+func sourceForRouter(cfg v1beta1.FRRConfiguration, index int) configSource {
+	return configSource{
+		object:   types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name},
+		path:     field.NewPath("spec", "bgp", "routers").Index(index),
+		priority: cfg.Spec.Priority,
+	}
+}
+
+// conflictError is returned when multiple configurations set different
+// values for the same field.
+type conflictError struct {
+	message string
+	// field is the name of the conflicting field of the router or the neighbor.
+	field string
+	// sources are the configurations contributing the conflicting values.
+	sources []configSource
+}
+
+func newConflict(field, format string, args ...interface{}) *conflictError {
+	return &conflictError{message: fmt.Sprintf(format, args...), field: field}
+}
+
+func (e *conflictError) Error() string {
+	if len(e.sources) == 0 {
+		return e.message
+	}
+	contributors := make([]string, 0, len(e.sources))
+	for _, s := range e.sources {
+		contributors = append(contributors, s.String())
+	}
+	return fmt.Sprintf("%s (set by %s)", e.message, strings.Join(contributors, ", "))
+}
+
+// FieldErrorFor returns the conflict as an error on the field of the given object, or nil
+// if the object is not one of the contributors.
+func (e *conflictError) FieldErrorFor(object types.NamespacedName) *field.Error {
+	for _, s := range e.sources {
+		if s.object == object {
+			return field.Forbidden(s.path, e.Error())
+		}
+	}
+	return nil
+}
+
+// mergeContext carries the state shared across the merge of all the configurations
+// applied to a node. The configurations are expected to be merged in decreasing
+// order of priority, so the current value of a scalar field always comes from the
+// configuration with the highest priority among the ones setting it.
+type mergeContext struct {
+	defaults neighborDefaults
+	// routers holds where each router was first defined, by VRF.
+	routers map[string]configSource
+	// routerIDs holds where the id of each router comes from, by VRF.
+	routerIDs map[string]configSource
+	// neighbors holds where each neighbor was first defined, by id.
+	neighbors map[string]configSource
+	// warnings are the conflicts resolved in favor of the configuration with the higher priority.
+	warnings []string
+}
+
+func newMergeContext(defaults neighborDefaults) *mergeContext {
+	return &mergeContext{
+		defaults:  defaults,
+		routers:   map[string]configSource{},
+		routerIDs: map[string]configSource{},
+		neighbors: map[string]configSource{},
 	}
+}
+
+// addRouter records the source of the given router and of its neighbors.
+func (c *mergeContext) addRouter(r *frr.RouterConfig, src configSource) {
+	if _, ok := c.routers[r.VRF]; !ok {
+		c.routers[r.VRF] = src
+	}
+	if _, ok := c.routerIDs[r.VRF]; !ok && r.RouterID != "" {
+		c.routerIDs[r.VRF] = src
+	}
+	for i, n := range r.Neighbors {
+		c.addNeighbor(n, src.neighbor(i))
+	}
+}
+
+func (c *mergeContext) addNeighbor(n *frr.NeighborConfig, src configSource) {
+	if _, ok := c.neighbors[n.ID()]; !ok {
+		c.neighbors[n.ID()] = src
+	}
+}
+
+// resolve handles a conflict between the current value of a field and the one coming from src.
+// The conflict is resolved in favor of the current value if it comes from a configuration with
+// a higher priority, otherwise the conflict is returned.
+func (c *mergeContext) resolve(conflict *conflictError, curr, src configSource) error {
+	if conflict.field != "" {
+		curr = curr.child(conflict.field)
+		src = src.child(conflict.field)
+	}
+	conflict.sources = []configSource{curr, src}
+	if src.priority >= curr.priority {
+		return conflict
+	}
+	c.warnings = append(c.warnings, fmt.Sprintf("%s, using the value of %s with priority %d", conflict, curr.object, curr.priority))
+	return nil
+}
 
-	if r.RouterID == "" {
+// Merges two router configs. toMerge comes from the given source.
+func mergeRouterConfigs(r, toMerge *frr.RouterConfig, src configSource, c *mergeContext) (*frr.RouterConfig, error) {
+	c.addRouter(r, src)
+	conflict := routersAreCompatible(r, toMerge)
+	if conflict != nil {
+		curr := c.routers[r.VRF]
+		if idSrc, ok := c.routerIDs[r.VRF]; ok && conflict.field == "id" {
+			curr = idSrc
+		}
+		err := c.resolve(conflict, curr, src)
+		if err != nil {
+			return nil, err
+		}
+		// The neighbors and the prefixes of a router losing the asn conflict are dropped,
+		// as they would run under a local asn they were not meant for (i.e. turning an
+		// ebgp session into an ibgp one).
+		if conflict.field == "asn" {
+			c.warnings[len(c.warnings)-1] += fmt.Sprintf(", ignoring the neighbors and the prefixes of %s", src)
+			return r, nil
+		}
+	}
+
+	if r.RouterID == "" && toMerge.RouterID != "" {
 		r.RouterID = toMerge.RouterID
+		c.routerIDs[r.VRF] = src
 	}
 
 	v4Prefixes := sets.New(append(r.IPV4Prefixes, toMerge.IPV4Prefixes...)...)
 	v6Prefixes := sets.New(append(r.IPV6Prefixes, toMerge.IPV6Prefixes...)...)
 
-	mergedNeighbors, err := mergeNeighbors(r.Neighbors, toMerge.Neighbors)
+	mergedNeighbors, err := mergeNeighbors(r.Neighbors, toMerge.Neighbors, src, c)
 	if err != nil {
 		return nil, err
 	}
@@ -42,8 +181,15 @@ func mergeRouterConfigs(r, toMerge *frr.RouterConfig) (*frr.RouterConfig, error)
 	return r, nil
 }
 
-// Merges two neighbors slices corresponding to the same router.
-func mergeNeighbors(curr, toMerge []*frr.NeighborConfig) ([]*frr.NeighborConfig, error) {
+// Merges two neighbors slices corresponding to the same router. toMerge comes from
+// the router with the given source.
+func mergeNeighbors(curr, toMerge []*frr.NeighborConfig, src configSource, c *mergeContext) ([]*frr.NeighborConfig, error) {
+	sources := map[*frr.NeighborConfig]configSource{}
+	for i, n := range toMerge {
+		sources[n] = src.neighbor(i)
+		c.addNeighbor(n, sources[n])
+	}
+
 	all := curr
 	all = append(all, toMerge...)
 	if len(all) == 0 {
@@ -59,19 +205,32 @@ func mergeNeighbors(curr, toMerge []*frr.NeighborConfig) ([]*frr.NeighborConfig,
 			continue
 		}
 
-		err := neighborsAreCompatible(curr, n)
-		if err != nil {
-			return nil, err
+		// The scalar fields of the current neighbor are left untouched when the
+		// conflict is resolved in its favor.
+		currSrc := c.neighbors[curr.ID()]
+		if conflict := neighborsAreCompatible(curr, n, c.defaults); conflict != nil {
+			err := c.resolve(conflict, currSrc, sources[n])
+			if err != nil {
+				return nil, err
+			}
 		}
 
+		var err error
 		curr.Outgoing, err = mergeAllowedOut(curr.Outgoing, n.Outgoing)
 		if err != nil {
-			return nil, fmt.Errorf("could not merge outgoing for neighbor %s vrf %s, err: %w", n.Addr, n.VRFName, err)
+			return nil, fmt.Errorf("could not merge outgoing for neighbor %s vrf %s (set by %s, %s), err: %w",
+				n.Addr, n.VRFName, currSrc.child("toAdvertise"), sources[n].child("toAdvertise"), err)
 		}
 
 		curr.Incoming = mergeAllowedIn(curr.Incoming, n.Incoming)
 
-		cleanNeighborDefaults(curr)
+		// The neighbor is disabled if any of the configurations disables it.
+		if n.Disabled && !curr.Disabled {
+			curr.Disabled = true
+			curr.DisabledMessage = n.DisabledMessage
+		}
+
+		cleanNeighborDefaults(curr, c.defaults)
 		mergedNeighbors[n.Addr] = curr
 	}
 
@@ -160,19 +319,22 @@ func mergeAllowedIn(r, toMerge frr.AllowedIn) frr.AllowedIn {
 
 // cleanNeighborDefaults unset any field whose value that is equal to the default
 // value for that field. This ensures consistency across conversions.
-func cleanNeighborDefaults(neigh *frr.NeighborConfig) {
-	if neigh.Port != nil && *neigh.Port == defaultBGPPort {
+func cleanNeighborDefaults(neigh *frr.NeighborConfig, defaults neighborDefaults) {
+	if neigh.Port != nil && *neigh.Port == defaults.port {
 		neigh.Port = nil
 	}
-	if neigh.HoldTime != nil && *neigh.HoldTime == defaultHoldTime {
+	if neigh.HoldTime != nil && *neigh.HoldTime == defaults.holdTime {
 		neigh.HoldTime = nil
 	}
-	if neigh.KeepaliveTime != nil && *neigh.KeepaliveTime == defaultKeepaliveTime {
+	if neigh.KeepaliveTime != nil && *neigh.KeepaliveTime == defaults.keepaliveTime {
 		neigh.KeepaliveTime = nil
 	}
-	if neigh.ConnectTime != nil && *neigh.ConnectTime == defaultConnectTime {
+	if neigh.ConnectTime != nil && *neigh.ConnectTime == defaults.connectTime {
 		neigh.ConnectTime = nil
 	}
+	if defaults.bfdProfile != nil && neigh.BFDProfile == defaults.bfdProfile.Name {
+		neigh.BFDProfile = ""
+	}
 }
 
 func mergeIncomingFilters(curr, toMerge []frr.IncomingFilter) []frr.IncomingFilter {
@@ -193,69 +355,69 @@ func mergeIncomingFilters(curr, toMerge []frr.IncomingFilter) []frr.IncomingFilt
 }
 
 // Verifies that two routers are compatible for merging.
-func routersAreCompatible(r, toMerge *frr.RouterConfig) error {
+func routersAreCompatible(r, toMerge *frr.RouterConfig) *conflictError {
 	if r.VRF != toMerge.VRF {
-		return fmt.Errorf("different VRFs specified (%s != %s)", r.VRF, toMerge.VRF)
+		return newConflict("vrf", "different VRFs specified (%s != %s)", r.VRF, toMerge.VRF)
 	}
 
 	if r.MyASN != toMerge.MyASN {
-		return fmt.Errorf("different asns (%d != %d) specified for same vrf: %s", r.MyASN, toMerge.MyASN, r.VRF)
+		return newConflict("asn", "different asns (%d != %d) specified for same vrf: %s", r.MyASN, toMerge.MyASN, r.VRF)
 	}
 
 	bothRouterIDsNonEmpty := r.RouterID != "" && toMerge.RouterID != ""
 	routerIDsDifferent := r.RouterID != toMerge.RouterID
 	if bothRouterIDsNonEmpty && routerIDsDifferent {
-		return fmt.Errorf("different router ids (%s != %s) specified for same vrf: %s", r.RouterID, toMerge.RouterID, r.VRF)
+		return newConflict("id", "different router ids (%s != %s) specified for same vrf: %s", r.RouterID, toMerge.RouterID, r.VRF)
 	}
 
 	return nil
 }
 
 // Verifies that two neighbors are compatible for merging, assuming they belong to the same router.
-func neighborsAreCompatible(n1, n2 *frr.NeighborConfig) error {
+func neighborsAreCompatible(n1, n2 *frr.NeighborConfig, defaults neighborDefaults) *conflictError {
 	if n1.Addr != n2.Addr {
-		return fmt.Errorf("neighbors with different addresses (%s != %s) are not compatible for merging", n1.Addr, n2.Addr)
+		return newConflict("address", "neighbors with different addresses (%s != %s) are not compatible for merging", n1.Addr, n2.Addr)
 	}
 
 	if n1.VRFName != n2.VRFName {
-		return fmt.Errorf("neighbors using a different VRF (%s != %s) are not compatible for merging", n1.VRFName, n2.VRFName)
+		return newConflict("", "neighbors using a different VRF (%s != %s) are not compatible for merging", n1.VRFName, n2.VRFName)
 	}
 
 	neighborKey := fmt.Sprintf("neighbor %s at vrf %s", n1.Addr, n1.VRFName)
 	if n1.ASN != n2.ASN {
-		return fmt.Errorf("multiple asns specified for %s", neighborKey)
+		return newConflict("asn", "multiple asns specified for %s", neighborKey)
 	}
 
-	if !ptrsEqual(n1.Port, n2.Port, defaultBGPPort) {
-		return fmt.Errorf("multiple ports specified for %s", neighborKey)
+	if !ptrsEqual(n1.Port, n2.Port, defaults.port) {
+		return newConflict("port", "multiple ports specified for %s", neighborKey)
 	}
 
 	if n1.SrcAddr != n2.SrcAddr {
-		return fmt.Errorf("multiple source addresses specified for %s", neighborKey)
+		return newConflict("", "multiple source addresses specified for %s", neighborKey)
 	}
 
 	if n1.Password != n2.Password {
-		return fmt.Errorf("multiple passwords specified for %s", neighborKey)
+		return newConflict("password", "multiple passwords specified for %s", neighborKey)
 	}
 
-	if n1.BFDProfile != n2.BFDProfile {
-		return fmt.Errorf("multiple bfd profiles specified for %s", neighborKey)
+	if bfdProfileOrDefault(n1.BFDProfile, defaults) != bfdProfileOrDefault(n2.BFDProfile, defaults) {
+		return newConflict("bfdProfile", "multiple bfd profiles specified for %s", neighborKey)
 	}
 
 	if n1.EBGPMultiHop != n2.EBGPMultiHop {
-		return fmt.Errorf("conflicting ebgp-multihop specified for %s", neighborKey)
+		return newConflict("ebgpMultiHop", "conflicting ebgp-multihop specified for %s", neighborKey)
 	}
 
-	if !ptrsEqual(n1.HoldTime, n2.HoldTime, defaultHoldTime) {
-		return fmt.Errorf("multiple hold times specified for %s", neighborKey)
+	if !ptrsEqual(n1.HoldTime, n2.HoldTime, defaults.holdTime) {
+		return newConflict("holdTime", "multiple hold times specified for %s", neighborKey)
 	}
 
-	if !ptrsEqual(n1.KeepaliveTime, n2.KeepaliveTime, defaultKeepaliveTime) {
-		return fmt.Errorf("multiple keepalive times specified for %s", neighborKey)
+	if !ptrsEqual(n1.KeepaliveTime, n2.KeepaliveTime, defaults.keepaliveTime) {
+		return newConflict("keepaliveTime", "multiple keepalive times specified for %s", neighborKey)
 	}
 
-	if !ptrsEqual(n1.ConnectTime, n2.ConnectTime, defaultConnectTime) {
-		return fmt.Errorf("multiple connect times specified for %s", neighborKey)
+	if !ptrsEqual(n1.ConnectTime, n2.ConnectTime, defaults.connectTime) {
+		return newConflict("connectTime", "multiple connect times specified for %s", neighborKey)
 	}
 
 	return nil
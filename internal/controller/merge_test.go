@@ -5,20 +5,28 @@ package controller
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
 	"github.com/metallb/frr-k8s/internal/frr"
 	"github.com/metallb/frr-k8s/internal/ipfamily"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/ptr"
 )
 
 func TestMergeRouters(t *testing.T) {
 	tests := []struct {
-		name     string
-		curr     *frr.RouterConfig
-		toMerge  *frr.RouterConfig
-		expected *frr.RouterConfig
-		err      error
+		name         string
+		curr         *frr.RouterConfig
+		toMerge      *frr.RouterConfig
+		currPriority int
+		priority     int
+		expected     *frr.RouterConfig
+		warnings     []string
+		err          error
 	}{
 		{
 			name: "Full - Multiple neigbors",
@@ -527,11 +535,109 @@ func TestMergeRouters(t *testing.T) {
 			},
 			err: fmt.Errorf("different router ids (%s != %s) specified for same vrf: %s", "192.0.2.1", "192.0.2.20", ""),
 		},
+		{
+			name: "Same VRF+ASN, different RouterIDs, resolved by priority",
+			curr: &frr.RouterConfig{
+				MyASN:        65001,
+				RouterID:     "192.0.2.1",
+				VRF:          "",
+				IPV4Prefixes: []string{"192.0.2.0/24"},
+				IPV6Prefixes: []string{},
+			},
+			toMerge: &frr.RouterConfig{
+				MyASN:        65001,
+				RouterID:     "192.0.2.20",
+				VRF:          "",
+				IPV4Prefixes: []string{"192.0.3.0/24"},
+				IPV6Prefixes: []string{},
+			},
+			currPriority: 10,
+			priority:     5,
+			expected: &frr.RouterConfig{
+				MyASN:        65001,
+				RouterID:     "192.0.2.1",
+				VRF:          "",
+				IPV4Prefixes: []string{"192.0.2.0/24", "192.0.3.0/24"},
+				IPV6Prefixes: []string{},
+				Neighbors:    []*frr.NeighborConfig{},
+			},
+			warnings: []string{"different router ids (192.0.2.1 != 192.0.2.20) specified for same vrf:  (set by ns/curr spec.bgp.routers[0].id, ns/tomerge spec.bgp.routers[1].id), using the value of ns/curr with priority 10"},
+		},
+		{
+			name: "Same VRF, different ASNs, resolved by priority",
+			curr: &frr.RouterConfig{
+				MyASN:        65001,
+				VRF:          "",
+				IPV4Prefixes: []string{"192.0.2.0/24"},
+				IPV6Prefixes: []string{},
+				Neighbors: []*frr.NeighborConfig{
+					{
+						IPFamily: ipfamily.IPv4,
+						Name:     "65002@192.0.2.2",
+						ASN:      65002,
+						Addr:     "192.0.2.2",
+					},
+				},
+			},
+			toMerge: &frr.RouterConfig{
+				MyASN:        65003,
+				VRF:          "",
+				IPV4Prefixes: []string{"192.0.3.0/24"},
+				IPV6Prefixes: []string{},
+				Neighbors: []*frr.NeighborConfig{
+					{
+						IPFamily: ipfamily.IPv4,
+						Name:     "65001@192.0.2.3",
+						ASN:      65001,
+						Addr:     "192.0.2.3",
+					},
+				},
+			},
+			currPriority: 10,
+			priority:     5,
+			expected: &frr.RouterConfig{
+				MyASN:        65001,
+				VRF:          "",
+				IPV4Prefixes: []string{"192.0.2.0/24"},
+				IPV6Prefixes: []string{},
+				Neighbors: []*frr.NeighborConfig{
+					{
+						IPFamily: ipfamily.IPv4,
+						Name:     "65002@192.0.2.2",
+						ASN:      65002,
+						Addr:     "192.0.2.2",
+					},
+				},
+			},
+			warnings: []string{"different asns (65001 != 65003) specified for same vrf:  (set by ns/curr spec.bgp.routers[0].asn, ns/tomerge spec.bgp.routers[1].asn), using the value of ns/curr with priority 10, ignoring the neighbors and the prefixes of ns/tomerge spec.bgp.routers[1]"},
+		},
+		{
+			name: "Same VRF+ASN, different RouterIDs, same priority",
+			curr: &frr.RouterConfig{
+				MyASN:        65001,
+				RouterID:     "192.0.2.1",
+				VRF:          "",
+				IPV4Prefixes: []string{},
+				IPV6Prefixes: []string{},
+			},
+			toMerge: &frr.RouterConfig{
+				MyASN:        65001,
+				RouterID:     "192.0.2.20",
+				VRF:          "",
+				IPV4Prefixes: []string{},
+				IPV6Prefixes: []string{},
+			},
+			currPriority: 5,
+			priority:     5,
+			err:          fmt.Errorf("different router ids (%s != %s) specified for same vrf: %s", "192.0.2.1", "192.0.2.20", ""),
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			merged, err := mergeRouterConfigs(test.curr, test.toMerge)
+			c := newMergeContext(frrNeighborDefaults)
+			c.addRouter(test.curr, configSource{object: types.NamespacedName{Namespace: "ns", Name: "curr"}, path: field.NewPath("spec", "bgp", "routers").Index(0), priority: test.currPriority})
+			merged, err := mergeRouterConfigs(test.curr, test.toMerge, configSource{object: types.NamespacedName{Namespace: "ns", Name: "tomerge"}, path: field.NewPath("spec", "bgp", "routers").Index(1), priority: test.priority}, c)
 			if test.err != nil && err == nil {
 				t.Fatalf("expected error, got nil")
 			}
@@ -541,17 +647,23 @@ func TestMergeRouters(t *testing.T) {
 			if diff := cmp.Diff(merged, test.expected); diff != "" {
 				t.Fatalf("config different from expected: %s", diff)
 			}
+			if diff := cmp.Diff(c.warnings, test.warnings); diff != "" {
+				t.Fatalf("warnings different from expected: %s", diff)
+			}
 		})
 	}
 }
 
 func TestMergeNeighbors(t *testing.T) {
 	tests := []struct {
-		name     string
-		curr     []*frr.NeighborConfig
-		toMerge  []*frr.NeighborConfig
-		expected []*frr.NeighborConfig
-		err      error
+		name         string
+		curr         []*frr.NeighborConfig
+		toMerge      []*frr.NeighborConfig
+		currPriority int
+		priority     int
+		expected     []*frr.NeighborConfig
+		warnings     []string
+		err          error
 	}{
 		{
 			name: "One neighbor, multiple configs",
@@ -1371,11 +1483,83 @@ func TestMergeNeighbors(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			name: "HoldTime / KeepAlive time, different values, resolved by priority",
+			curr: []*frr.NeighborConfig{
+				{
+					IPFamily:      ipfamily.IPv4,
+					Name:          "65040@192.0.1.20",
+					ASN:           65040,
+					Addr:          "192.0.1.20",
+					HoldTime:      ptr.To(uint64(90)),
+					KeepaliveTime: ptr.To(uint64(30)),
+				},
+			},
+			toMerge: []*frr.NeighborConfig{
+				{
+					IPFamily: ipfamily.IPv4,
+					Name:     "65040@192.0.1.20",
+					ASN:      65040,
+					Addr:     "192.0.1.20",
+					Incoming: frr.AllowedIn{
+						All: true,
+					},
+				},
+			},
+			currPriority: 10,
+			priority:     5,
+			expected: []*frr.NeighborConfig{
+				{
+					IPFamily:      ipfamily.IPv4,
+					Name:          "65040@192.0.1.20",
+					ASN:           65040,
+					Addr:          "192.0.1.20",
+					HoldTime:      ptr.To(uint64(90)),
+					KeepaliveTime: ptr.To(uint64(30)),
+					Outgoing: frr.AllowedOut{
+						PrefixesV4: []frr.OutgoingFilter{},
+						PrefixesV6: []frr.OutgoingFilter{},
+					},
+					Incoming: frr.AllowedIn{
+						All:        true,
+						PrefixesV4: []frr.IncomingFilter{},
+						PrefixesV6: []frr.IncomingFilter{},
+					},
+				},
+			},
+			warnings: []string{"multiple hold times specified for neighbor 192.0.1.20 at vrf  (set by ns/curr spec.bgp.routers[0].neighbors[0].holdTime, ns/tomerge spec.bgp.routers[1].neighbors[0].holdTime), using the value of ns/curr with priority 10"},
+		},
+		{
+			name: "HoldTime / KeepAlive time, different values, the higher priority is merged",
+			curr: []*frr.NeighborConfig{
+				{
+					IPFamily:      ipfamily.IPv4,
+					Name:          "65040@192.0.1.20",
+					ASN:           65040,
+					Addr:          "192.0.1.20",
+					HoldTime:      ptr.To(uint64(90)),
+					KeepaliveTime: ptr.To(uint64(30)),
+				},
+			},
+			toMerge: []*frr.NeighborConfig{
+				{
+					IPFamily: ipfamily.IPv4,
+					Name:     "65040@192.0.1.20",
+					ASN:      65040,
+					Addr:     "192.0.1.20",
+				},
+			},
+			currPriority: 5,
+			priority:     10,
+			err:          fmt.Errorf("multiple hold times specified for neighbor 192.0.1.20 at vrf "),
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			merged, err := mergeNeighbors(test.curr, test.toMerge)
+			c := newMergeContext(frrNeighborDefaults)
+			c.addRouter(&frr.RouterConfig{Neighbors: test.curr}, configSource{object: types.NamespacedName{Namespace: "ns", Name: "curr"}, path: field.NewPath("spec", "bgp", "routers").Index(0), priority: test.currPriority})
+			merged, err := mergeNeighbors(test.curr, test.toMerge, configSource{object: types.NamespacedName{Namespace: "ns", Name: "tomerge"}, path: field.NewPath("spec", "bgp", "routers").Index(1), priority: test.priority}, c)
 			if test.err != nil && err == nil {
 				t.Fatalf("expected error, got nil")
 			}
@@ -1385,6 +1569,54 @@ func TestMergeNeighbors(t *testing.T) {
 			if diff := cmp.Diff(merged, test.expected); diff != "" {
 				t.Fatalf("config different from expected: %s", diff)
 			}
+			if diff := cmp.Diff(c.warnings, test.warnings); diff != "" {
+				t.Fatalf("warnings different from expected: %s", diff)
+			}
 		})
 	}
 }
+
+func TestConflictContributors(t *testing.T) {
+	config := func(name string, holdTime time.Duration) v1beta1.FRRConfiguration {
+		return v1beta1.FRRConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "frr-k8s-system"},
+			Spec: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{
+						{ASN: 65001},
+						{
+							ASN: 65001,
+							VRF: "red",
+							Neighbors: []v1beta1.Neighbor{
+								{ASN: 65002, Address: "192.0.2.1"},
+								{
+									ASN:           65003,
+									Address:       "192.0.2.2",
+									HoldTime:      &metav1.Duration{Duration: holdTime},
+									KeepaliveTime: &metav1.Duration{Duration: 10 * time.Second},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	resources := ClusterResources{
+		FRRConfigs: []v1beta1.FRRConfiguration{
+			config("first", 90*time.Second),
+			config("second", 30*time.Second),
+		},
+	}
+	_, _, err := apiToFRR(resources, nil)
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	expected := "multiple hold times specified for neighbor 192.0.2.2 at vrf red (set by " +
+		"frr-k8s-system/first spec.bgp.routers[1].neighbors[1].holdTime, " +
+		"frr-k8s-system/second spec.bgp.routers[1].neighbors[1].holdTime)"
+	if err.Error() != expected {
+		t.Fatalf("expected error %q, got %q", expected, err.Error())
+	}
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"net"
+
+	"github.com/metallb/frr-k8s/api/v1beta1"
+	"github.com/metallb/frr-k8s/internal/frr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// nodeConfigInput is what the FRR configuration of a node is built from.
+type nodeConfigInput struct {
+	configs []v1beta1.FRRConfiguration
+	node    *corev1.Node
+	// resources are the cluster resources the configurations are translated with.
+	// Their FRRConfigs, PodCIDRs and HealthyProbes are filled by buildNodeConfig.
+	resources ClusterResources
+	// healthyProbes returns the healthy probes out of the ones of the given configurations.
+	healthyProbes func([]v1beta1.FRRConfiguration) sets.Set[string]
+	alwaysBlock   []net.IPNet
+	drainMode     frr.DrainMode
+}
+
+// nodeConfigResult is the outcome of building the FRR configuration of a node.
+type nodeConfigResult struct {
+	config *frr.Config
+	// warnings are the conflicts between the configurations resolved by priority.
+	warnings []string
+	// ignored are the configurations not allowed by the cluster policy.
+	ignored []string
+	// draining tells if the advertised routes were drained according to the drain mode.
+	draining bool
+}
+
+// nodeSelectorError is returned when the node selector of a configuration can't be parsed.
+type nodeSelectorError struct {
+	error
+}
+
+func (e nodeSelectorError) Unwrap() error { return e.error }
+
+// buildNodeConfig picks the configurations matching the node, resolves their node variables,
+// drops the ones not allowed by the cluster policy, translates them into the FRR configuration
+// and drains it if the node is being drained. The ignored configurations are returned also
+// when the translation fails.
+func buildNodeConfig(in nodeConfigInput) (nodeConfigResult, error) {
+	res := nodeConfigResult{}
+	cfgs, err := configsForNode(in.configs, in.node.Labels)
+	if err != nil {
+		return res, nodeSelectorError{err}
+	}
+	cfgs, err = substituteNodeVariables(cfgs, in.node)
+	if err != nil {
+		return res, err
+	}
+	cfgs, res.ignored = configsAllowedByPolicy(cfgs, in.resources.Policy)
+
+	resources := in.resources
+	resources.FRRConfigs = cfgs
+	resources.PodCIDRs = podCIDRsForNode(in.node)
+	resources.HealthyProbes = in.healthyProbes(cfgs)
+	res.config, res.warnings, err = apiToFRR(resources, in.alwaysBlock)
+	if err != nil {
+		return res, err
+	}
+
+	if in.drainMode != frr.DrainDisabled && nodeIsDraining(in.node) {
+		res.config = frr.DrainedConfig(res.config, in.drainMode)
+		res.draining = true
+	}
+	return res, nil
+}
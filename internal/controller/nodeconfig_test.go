@@ -0,0 +1,73 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/metallb/frr-k8s/api/v1beta1"
+	"github.com/metallb/frr-k8s/internal/frr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestBuildNodeConfig(t *testing.T) {
+	config := func(name string, selector metav1.LabelSelector) v1beta1.FRRConfiguration {
+		return v1beta1.FRRConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "frr-k8s-system"},
+			Spec: v1beta1.FRRConfigurationSpec{
+				NodeSelector: selector,
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{
+						{
+							ASN:      65001,
+							Prefixes: []string{"192.0.2.0/24"},
+						},
+					},
+				},
+			},
+		}
+	}
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"rack": "a"}},
+		Spec:       v1.NodeSpec{Unschedulable: true},
+	}
+	healthy := func([]v1beta1.FRRConfiguration) sets.Set[string] { return sets.New[string]() }
+
+	t.Run("drains the config of a draining node", func(t *testing.T) {
+		res, err := buildNodeConfig(nodeConfigInput{
+			configs: []v1beta1.FRRConfiguration{
+				config("rack-a", metav1.LabelSelector{MatchLabels: map[string]string{"rack": "a"}}),
+			},
+			node:          node,
+			healthyProbes: healthy,
+			drainMode:     frr.DrainWithdraw,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !res.draining {
+			t.Fatalf("expecting the node to be draining")
+		}
+		if len(res.config.Routers) != 1 || len(res.config.Routers[0].IPV4Prefixes) != 0 {
+			t.Fatalf("expecting the prefixes to be withdrawn, got %+v", res.config.Routers)
+		}
+	})
+
+	t.Run("invalid node selector", func(t *testing.T) {
+		invalid := metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "rack", Operator: "foo"}},
+		}
+		_, err := buildNodeConfig(nodeConfigInput{
+			configs:       []v1beta1.FRRConfiguration{config("invalid", invalid)},
+			node:          node,
+			healthyProbes: healthy,
+		})
+		var selectorErr nodeSelectorError
+		if !errors.As(err, &selectorErr) {
+			t.Fatalf("expecting a node selector error, got %v", err)
+		}
+	})
+}
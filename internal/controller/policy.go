@@ -0,0 +1,90 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"fmt"
+
+	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// policySpec returns the spec of the FRRPolicy instance taken into account, if any.
+func policySpec(policies []v1beta1.FRRPolicy) *v1beta1.FRRPolicySpec {
+	for i := range policies {
+		if policies[i].Name == v1beta1.FRRPolicyName {
+			return &policies[i].Spec
+		}
+	}
+	return nil
+}
+
+// policyAllowed checks that the given configuration complies with both the raw config
+// and the tenant policies. The configuration is expected to have its node variables
+// already resolved.
+func policyAllowed(spec *v1beta1.FRRPolicySpec, cfg v1beta1.FRRConfiguration) error {
+	if err := rawConfigAllowed(spec, cfg); err != nil {
+		return err
+	}
+	return tenancyAllowed(spec, cfg)
+}
+
+// configsAllowedByPolicy splits the given configurations between the ones allowed by the
+// cluster policy and the ones to be ignored, returned in the form "namespace/name: reason".
+func configsAllowedByPolicy(cfgs []v1beta1.FRRConfiguration, spec *v1beta1.FRRPolicySpec) ([]v1beta1.FRRConfiguration, []string) {
+	allowed := []v1beta1.FRRConfiguration{}
+	var ignored []string
+	for _, cfg := range cfgs {
+		if err := policyAllowed(spec, cfg); err != nil {
+			ignored = append(ignored, fmt.Sprintf("%s: %s", types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}, errorMessage(err)))
+			continue
+		}
+		allowed = append(allowed, cfg)
+	}
+	return allowed, ignored
+}
+
+// errorMessage returns the message of the given error, without the object it refers to.
+func errorMessage(err error) string {
+	convErr, ok := err.(*ConversionError)
+	if !ok {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s: %s", convErr.Field, convErr.Err)
+}
+
+// ValidatePolicy checks that the given configuration, with its node variables resolved
+// against each of the given nodes, complies with the cluster policy.
+func ValidatePolicy(cfg *v1beta1.FRRConfiguration, resources ...client.ObjectList) error {
+	var spec *v1beta1.FRRPolicySpec
+	nodes := []corev1.Node{}
+	for _, list := range resources {
+		switch l := list.(type) {
+		case *corev1.NodeList:
+			nodes = append(nodes, l.Items...)
+		case *v1beta1.FRRPolicyList:
+			spec = policySpec(l.Items)
+		}
+	}
+
+	cfgKey := types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}
+	if len(nodes) == 0 {
+		if err := policyAllowed(spec, *cfg); err != nil {
+			return inObject(cfgKey, err)
+		}
+		return nil
+	}
+	for i := range nodes {
+		resolved := cfg.DeepCopy()
+		if err := substituteConfig(resolved, &nodes[i]); err != nil {
+			// The failure is reported when validating the configuration.
+			continue
+		}
+		if err := policyAllowed(spec, *resolved); err != nil {
+			return inObject(cfgKey, err)
+		}
+	}
+	return nil
+}
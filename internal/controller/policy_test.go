@@ -0,0 +1,121 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigsAllowedByPolicy(t *testing.T) {
+	config := func(name, raw string) v1beta1.FRRConfiguration {
+		return v1beta1.FRRConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{{ASN: 65001}},
+				},
+				Raw: v1beta1.RawConfig{Config: raw},
+			},
+		}
+	}
+	policy := &v1beta1.FRRPolicySpec{
+		RawConfig: &v1beta1.RawConfigPolicy{AllowedCommands: []string{"router bgp", "neighbor"}},
+	}
+
+	cfgs := []v1beta1.FRRConfiguration{
+		config("plain", ""),
+		config("allowed", "router bgp 65001\n  neighbor 192.0.2.2 description foo"),
+		config("forbidden", "router bgp 65001\n  no bgp default ipv4-unicast"),
+	}
+	allowed, ignored := configsAllowedByPolicy(cfgs, policy)
+	allowedNames := []string{}
+	for _, cfg := range allowed {
+		allowedNames = append(allowedNames, cfg.Name)
+	}
+	if diff := cmp.Diff([]string{"plain", "allowed"}, allowedNames); diff != "" {
+		t.Fatalf("allowed configs different from expected: %s", diff)
+	}
+	expectedIgnored := []string{`default/forbidden: spec.raw.rawConfig: command "no bgp default ipv4-unicast" is not allowed by the cluster policy`}
+	if diff := cmp.Diff(expectedIgnored, ignored); diff != "" {
+		t.Fatalf("ignored configs different from expected: %s", diff)
+	}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	cfg := &v1beta1.FRRConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cfg",
+			Namespace: "tenant-a",
+		},
+		Spec: v1beta1.FRRConfigurationSpec{
+			BGP: v1beta1.BGPConfig{
+				Routers: []v1beta1.Router{
+					{
+						ASN: 65001,
+						VRF: "${node.labels['vrf']}",
+					},
+				},
+			},
+		},
+	}
+	policies := &v1beta1.FRRPolicyList{
+		Items: []v1beta1.FRRPolicy{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec: v1beta1.FRRPolicySpec{
+					Tenants: []v1beta1.TenantPolicy{
+						{
+							Namespaces: []string{"tenant-a"},
+							VRFs:       []string{"red"},
+						},
+					},
+				},
+			},
+		},
+	}
+	node := func(vrf string) *corev1.NodeList {
+		return &corev1.NodeList{
+			Items: []corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "node",
+						Labels: map[string]string{"vrf": vrf},
+					},
+				},
+			},
+		}
+	}
+
+	if err := ValidatePolicy(cfg, node("red"), policies); err != nil {
+		t.Fatalf("not expecting error, got %v", err)
+	}
+	err := ValidatePolicy(cfg, node("blue"), policies)
+	if err == nil {
+		t.Fatalf("expecting error, got nil")
+	}
+	if ReasonFor(err) != ReasonForbidden {
+		t.Fatalf("expecting reason %s, got %s", ReasonForbidden, ReasonFor(err))
+	}
+
+	// The raw config is checked once the node variables are resolved too.
+	withRaw := cfg.DeepCopy()
+	withRaw.Spec.Raw.Config = "${node.labels['command']}"
+	policies.Items[0].Spec.RawConfig = &v1beta1.RawConfigPolicy{AllowedCommands: []string{"router bgp"}}
+	nodes := node("red")
+	nodes.Items[0].Labels["command"] = "ip route 0.0.0.0/0 192.0.2.1"
+	err = ValidatePolicy(withRaw, nodes, policies)
+	if err == nil {
+		t.Fatalf("expecting error, got nil")
+	}
+	if ReasonFor(err) != ReasonForbidden {
+		t.Fatalf("expecting reason %s, got %s", ReasonForbidden, ReasonFor(err))
+	}
+}
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"strings"
+
+	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// rawConfigAllowed checks that the raw configuration of the given configuration
+// complies with the raw config policy of the given FRRPolicy spec, if any.
+func rawConfigAllowed(spec *v1beta1.FRRPolicySpec, cfg v1beta1.FRRConfiguration) error {
+	if spec == nil || spec.RawConfig == nil {
+		return nil
+	}
+	policy := spec.RawConfig
+	rawPath := field.NewPath("spec", "raw")
+
+	if policy.Disabled {
+		return newConversionError(ReasonForbidden, rawPath, "raw configuration is disabled by the cluster policy")
+	}
+	if len(policy.AllowedNamespaces) > 0 && !sets.New(policy.AllowedNamespaces...).Has(cfg.Namespace) {
+		return newConversionError(ReasonForbidden, rawPath, "raw configuration is not allowed in namespace %s by the cluster policy", cfg.Namespace)
+	}
+	if len(policy.AllowedCommands) == 0 {
+		return nil
+	}
+
+	if cmd := forbiddenCommand(cfg.Spec.Raw.Config, policy.AllowedCommands); cmd != "" {
+		return newConversionError(ReasonForbidden, rawPath.Child("rawConfig"), "command %q is not allowed by the cluster policy", cmd)
+	}
+	for i, s := range cfg.Spec.Raw.Scoped {
+		if cmd := forbiddenCommand(s.Config, policy.AllowedCommands); cmd != "" {
+			return newConversionError(ReasonForbidden, rawPath.Child("scoped").Index(i).Child("rawConfig"), "command %q is not allowed by the cluster policy", cmd)
+		}
+	}
+	return nil
+}
+
+// forbiddenCommand returns the first line of the given raw configuration that does not
+// start with any of the allowed command prefixes, or an empty string if all of them do.
+func forbiddenCommand(raw string, allowed []string) string {
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "!") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		isAllowed := false
+		for _, a := range allowed {
+			if strings.HasPrefix(line, a) {
+				isAllowed = true
+				break
+			}
+		}
+		if !isAllowed {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"testing"
+
+	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRawConfigAllowed(t *testing.T) {
+	cfg := v1beta1.FRRConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "frr-k8s-system",
+		},
+		Spec: v1beta1.FRRConfigurationSpec{
+			Raw: v1beta1.RawConfig{
+				Config: "! comment\nrouter bgp 65001\n\n  neighbor 192.0.2.2 description foo",
+				Scoped: []v1beta1.ScopedRawConfig{
+					{
+						ASN:    65001,
+						Config: "  bgp bestpath as-path multipath-relax",
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		policy   *v1beta1.RawConfigPolicy
+		mustFail bool
+	}{
+		{
+			name: "no policy",
+		},
+		{
+			name:     "disabled",
+			policy:   &v1beta1.RawConfigPolicy{Disabled: true},
+			mustFail: true,
+		},
+		{
+			name:   "allowed namespace",
+			policy: &v1beta1.RawConfigPolicy{AllowedNamespaces: []string{"frr-k8s-system"}},
+		},
+		{
+			name:   "allowed commands",
+			policy: &v1beta1.RawConfigPolicy{AllowedCommands: []string{"router bgp", "neighbor", "bgp bestpath"}},
+		},
+		{
+			name:     "scoped command not allowed",
+			policy:   &v1beta1.RawConfigPolicy{AllowedCommands: []string{"router bgp", "neighbor"}},
+			mustFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := rawConfigAllowed(&v1beta1.FRRPolicySpec{RawConfig: test.policy}, cfg)
+			if test.mustFail && err == nil {
+				t.Fatalf("expecting error, got nil")
+			}
+			if !test.mustFail && err != nil {
+				t.Fatalf("not expecting error, got %s", err)
+			}
+		})
+	}
+}
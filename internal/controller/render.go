@@ -0,0 +1,103 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"net"
+
+	"github.com/metallb/frr-k8s/api/v1beta1"
+	"github.com/metallb/frr-k8s/internal/frr"
+	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// RenderResources are the resources the configuration of a node is rendered
+// from, when rendering it without a cluster.
+type RenderResources struct {
+	FRRConfigs []v1beta1.FRRConfiguration
+	Node       *corev1.Node
+	// Secrets are the secrets of the namespace frr-k8s is deployed in.
+	Secrets  []corev1.Secret
+	Defaults []v1beta1.FRRDefaults
+	Policies []v1beta1.FRRPolicy
+	// Services and EndpointSlices are the ones the services advertised by the routers
+	// are picked from, and Pods the ones the pod conditions are checked against.
+	Services       []corev1.Service
+	EndpointSlices []discovery.EndpointSlice
+	Pods           []corev1.Pod
+	AlwaysBlock    []net.IPNet
+	DrainMode      frr.DrainMode
+}
+
+// RenderResult is the outcome of rendering the configuration of a node.
+type RenderResult struct {
+	// Config is the content of the FRR configuration file.
+	Config string
+	// Warnings are the conflicts between the configurations resolved by priority.
+	Warnings []string
+	// IgnoredConfigurations are the configurations not allowed by the cluster policy.
+	IgnoredConfigurations []string
+}
+
+// Render translates the given resources into the FRR configuration of the node, the same
+// way the reconciler does. As no probe can be run, the conditional prefixes are rendered
+// as if their probes were healthy.
+func Render(resources RenderResources) (RenderResult, error) {
+	res := RenderResult{}
+	node := resources.Node
+	if node == nil {
+		node = &corev1.Node{}
+	}
+
+	defaults := defaultsSpec(resources.Defaults)
+	var config *frr.Config
+	if len(resources.FRRConfigs) == 0 {
+		var err error
+		config, _, err = apiToFRR(ClusterResources{Defaults: defaults}, resources.AlwaysBlock)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		secrets := map[string]corev1.Secret{}
+		for _, s := range resources.Secrets {
+			secrets[s.Name] = s
+		}
+		nodeConfig, err := buildNodeConfig(nodeConfigInput{
+			configs: resources.FRRConfigs,
+			node:    node,
+			resources: ClusterResources{
+				PasswordSecrets: secrets,
+				Services:        servicesForNode(resources.Services, resources.EndpointSlices, node.Name),
+				Pods:            podsForNode(resources.Pods, node.Name),
+				Defaults:        defaults,
+				Policy:          policySpec(resources.Policies),
+			},
+			healthyProbes: func(cfgs []v1beta1.FRRConfiguration) sets.Set[string] {
+				healthy := sets.New[string]()
+				for _, p := range probesForConfigs(cfgs) {
+					healthy.Insert(p.Key())
+				}
+				return healthy
+			},
+			alwaysBlock: resources.AlwaysBlock,
+			drainMode:   resources.DrainMode,
+		})
+		res.IgnoredConfigurations = nodeConfig.ignored
+		if err != nil {
+			return res, err
+		}
+		config, res.Warnings = nodeConfig.config, nodeConfig.warnings
+	}
+
+	config.Hostname = node.Name
+	if config.Loglevel == "" {
+		config.Loglevel = frr.LogLevelToFRR("")
+	}
+	rendered, err := frr.RenderConfig(config)
+	if err != nil {
+		return res, err
+	}
+	res.Config = rendered
+	return res, nil
+}
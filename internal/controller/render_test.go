@@ -0,0 +1,215 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/metallb/frr-k8s/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRender(t *testing.T) {
+	config := func(name string, selector map[string]string, asn uint32) v1beta1.FRRConfiguration {
+		return v1beta1.FRRConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "frr-k8s-system"},
+			Spec: v1beta1.FRRConfigurationSpec{
+				NodeSelector: metav1.LabelSelector{MatchLabels: selector},
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{
+						{
+							ASN: asn,
+							Neighbors: []v1beta1.Neighbor{
+								{
+									ASN:            65010,
+									Address:        "192.0.2.10",
+									PasswordSecret: v1.SecretReference{Name: "bgp-password"},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"rack": "a"}},
+	}
+	secret := v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "bgp-password", Namespace: "frr-k8s-system"},
+		Type:       v1.SecretTypeBasicAuth,
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+
+	tests := []struct {
+		name            string
+		resources       RenderResources
+		expectedLines   []string
+		expectedIgnored []string
+		expectedReason  ErrorReason
+	}{
+		{
+			name: "selects the configurations of the node",
+			resources: RenderResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					config("rack-a", map[string]string{"rack": "a"}, 65001),
+					config("rack-b", map[string]string{"rack": "b"}, 65002),
+				},
+				Node:    node,
+				Secrets: []v1.Secret{secret},
+			},
+			expectedLines: []string{
+				"hostname node1",
+				"router bgp 65001",
+				"neighbor 192.0.2.10 password secret",
+			},
+		},
+		{
+			name: "no configurations",
+			resources: RenderResources{
+				Node: node,
+			},
+			expectedLines: []string{
+				"hostname node1",
+			},
+		},
+		{
+			name: "no configurations with defaults",
+			resources: RenderResources{
+				Node: node,
+				Defaults: []v1beta1.FRRDefaults{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: v1beta1.FRRDefaultsName},
+						Spec:       v1beta1.FRRDefaultsSpec{LogLevel: "debug"},
+					},
+				},
+			},
+			expectedLines: []string{
+				"hostname node1",
+				"log file /etc/frr/frr.log debugging",
+			},
+		},
+		{
+			name: "services and pods",
+			resources: RenderResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "services", Namespace: "frr-k8s-system"},
+						Spec: v1beta1.FRRConfigurationSpec{
+							BGP: v1beta1.BGPConfig{
+								Routers: []v1beta1.Router{
+									{
+										ASN:             65001,
+										ServiceSelector: &metav1.LabelSelector{},
+										ConditionalPrefixes: []v1beta1.ConditionalPrefixes{
+											{
+												Prefixes: []string{"198.51.100.0/24"},
+												Condition: v1beta1.PrefixCondition{
+													Pod: &v1beta1.PodCondition{
+														Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "backend"}},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				Node: node,
+				Services: []v1.Service{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"},
+						Status: v1.ServiceStatus{
+							LoadBalancer: v1.LoadBalancerStatus{
+								Ingress: []v1.LoadBalancerIngress{{IP: "192.0.2.100"}},
+							},
+						},
+					},
+				},
+				Pods: []v1.Pod{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "default", Labels: map[string]string{"app": "backend"}},
+						Spec:       v1.PodSpec{NodeName: "node1"},
+						Status: v1.PodStatus{
+							Phase:      v1.PodRunning,
+							Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+						},
+					},
+				},
+			},
+			expectedLines: []string{
+				"network 192.0.2.100/32",
+				"network 198.51.100.0/24",
+			},
+		},
+		{
+			name: "configurations not allowed by the policy",
+			resources: RenderResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					config("rack-a", map[string]string{"rack": "a"}, 65001),
+				},
+				Node: node,
+				Policies: []v1beta1.FRRPolicy{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: v1beta1.FRRPolicyName},
+						Spec: v1beta1.FRRPolicySpec{
+							Tenants: []v1beta1.TenantPolicy{
+								{Namespaces: []string{"frr-k8s-system"}, VRFs: []string{"red"}},
+							},
+						},
+					},
+				},
+			},
+			expectedLines: []string{
+				"hostname node1",
+			},
+			expectedIgnored: []string{
+				"frr-k8s-system/rack-a: spec.bgp.routers[0].vrf: vrf default is not allowed for namespace frr-k8s-system by the tenancy policy",
+			},
+		},
+		{
+			name: "missing secret",
+			resources: RenderResources{
+				FRRConfigs: []v1beta1.FRRConfiguration{
+					config("rack-a", map[string]string{"rack": "a"}, 65001),
+				},
+				Node: node,
+			},
+			expectedReason: ReasonNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := Render(test.resources)
+			if test.expectedReason != "" {
+				if err == nil {
+					t.Fatalf("expecting error, got nil")
+				}
+				if reason := ReasonFor(err); reason != test.expectedReason {
+					t.Fatalf("expecting reason %s, got %s: %v", test.expectedReason, reason, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, l := range test.expectedLines {
+				if !strings.Contains(res.Config, l) {
+					t.Fatalf("expecting %q in the rendered config:\n%s", l, res.Config)
+				}
+			}
+			if diff := cmp.Diff(test.expectedIgnored, res.IgnoredConfigurations); diff != "" {
+				t.Fatalf("ignored configurations different from expected: %s", diff)
+			}
+			if strings.Contains(res.Config, "router bgp 65002") {
+				t.Fatalf("unexpected configuration of another node:\n%s", res.Config)
+			}
+		})
+	}
+}
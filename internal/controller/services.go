@@ -0,0 +1,90 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// servicesForNode returns the services that can be advertised from the given node.
+// Services with externalTrafficPolicy Local are advertised only if they have at least
+// one ready endpoint on the node.
+func servicesForNode(services []corev1.Service, slices []discovery.EndpointSlice, nodeName string) []corev1.Service {
+	withLocalEndpoints := sets.New[string]()
+	for _, s := range slices {
+		serviceName, ok := s.Labels[discovery.LabelServiceName]
+		if !ok {
+			continue
+		}
+		for _, ep := range s.Endpoints {
+			if ep.NodeName == nil || *ep.NodeName != nodeName {
+				continue
+			}
+			if ep.Conditions.Ready != nil && !*ep.Conditions.Ready {
+				continue
+			}
+			withLocalEndpoints.Insert(s.Namespace + "/" + serviceName)
+			break
+		}
+	}
+
+	res := make([]corev1.Service, 0)
+	for _, svc := range services {
+		if svc.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyLocal &&
+			!withLocalEndpoints.Has(svc.Namespace+"/"+svc.Name) {
+			continue
+		}
+		res = append(res, svc)
+	}
+	return res
+}
+
+// prefixesForServices returns the host prefixes corresponding to the LoadBalancer ingress IPs
+// and the ExternalIPs of the services matching the given selector.
+func prefixesForServices(services []corev1.Service, serviceSelector *metav1.LabelSelector) ([]string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(serviceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service selector: %w", err)
+	}
+
+	res := sets.New[string]()
+	for _, svc := range services {
+		if !selector.Matches(labels.Set(svc.Labels)) {
+			continue
+		}
+		ips := append([]string{}, svc.Spec.ExternalIPs...)
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			ips = append(ips, ingress.IP)
+		}
+		for _, ip := range ips {
+			if ip == "" {
+				continue
+			}
+			prefix, err := hostPrefix(ip)
+			if err != nil {
+				return nil, fmt.Errorf("service %s/%s: %w", svc.Namespace, svc.Name, err)
+			}
+			res.Insert(prefix)
+		}
+	}
+
+	return sets.List(res), nil
+}
+
+func hostPrefix(ip string) (string, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return "", fmt.Errorf("invalid ip %s", ip)
+	}
+	if parsed.To4() != nil {
+		return fmt.Sprintf("%s/32", parsed.String()), nil
+	}
+	return fmt.Sprintf("%s/128", parsed.String()), nil
+}
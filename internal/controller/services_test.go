@@ -0,0 +1,99 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestServicesForNode(t *testing.T) {
+	service := func(name string, policy v1.ServiceExternalTrafficPolicy) v1.Service {
+		return v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       v1.ServiceSpec{ExternalTrafficPolicy: policy},
+		}
+	}
+	slice := func(service, node string, ready bool) discovery.EndpointSlice {
+		return discovery.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      service + "-slice",
+				Namespace: "default",
+				Labels:    map[string]string{discovery.LabelServiceName: service},
+			},
+			Endpoints: []discovery.Endpoint{
+				{
+					NodeName:   ptr.To(node),
+					Conditions: discovery.EndpointConditions{Ready: ptr.To(ready)},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		services []v1.Service
+		slices   []discovery.EndpointSlice
+		expected []string
+	}{
+		{
+			name:     "cluster policy, no endpoints",
+			services: []v1.Service{service("svc", v1.ServiceExternalTrafficPolicyCluster)},
+			expected: []string{"svc"},
+		},
+		{
+			name:     "local policy, no endpoints",
+			services: []v1.Service{service("svc", v1.ServiceExternalTrafficPolicyLocal)},
+			expected: []string{},
+		},
+		{
+			name:     "local policy, ready endpoint on this node",
+			services: []v1.Service{service("svc", v1.ServiceExternalTrafficPolicyLocal)},
+			slices:   []discovery.EndpointSlice{slice("svc", testNodeName, true)},
+			expected: []string{"svc"},
+		},
+		{
+			name:     "local policy, not ready endpoint on this node",
+			services: []v1.Service{service("svc", v1.ServiceExternalTrafficPolicyLocal)},
+			slices:   []discovery.EndpointSlice{slice("svc", testNodeName, false)},
+			expected: []string{},
+		},
+		{
+			name:     "local policy, ready endpoint on another node",
+			services: []v1.Service{service("svc", v1.ServiceExternalTrafficPolicyLocal)},
+			slices:   []discovery.EndpointSlice{slice("svc", "othernode", true)},
+			expected: []string{},
+		},
+		{
+			name: "mixed",
+			services: []v1.Service{
+				service("svc1", v1.ServiceExternalTrafficPolicyLocal),
+				service("svc2", v1.ServiceExternalTrafficPolicyLocal),
+				service("svc3", v1.ServiceExternalTrafficPolicyCluster),
+			},
+			slices: []discovery.EndpointSlice{
+				slice("svc1", "othernode", true),
+				slice("svc2", testNodeName, true),
+			},
+			expected: []string{"svc2", "svc3"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := servicesForNode(test.services, test.slices, testNodeName)
+			names := []string{}
+			for _, s := range res {
+				names = append(names, s.Name)
+			}
+			if diff := cmp.Diff(test.expected, names); diff != "" {
+				t.Fatalf("services different from expected: %s", diff)
+			}
+		})
+	}
+}
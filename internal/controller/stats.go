@@ -38,9 +38,16 @@ var (
 		Name:      "config_stale_bool",
 		Help:      "1 if running on a stale configuration, because the latest config failed to load.",
 	})
+
+	conversionErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: Subsystem,
+		Name:      "conversion_errors_total",
+		Help:      "Number of failures converting the configurations, by reason.",
+	}, []string{"reason"})
 )
 
 func init() {
 	// Register custom metrics with the global prometheus registry
-	metrics.Registry.MustRegister(updates, updateErrors, configLoaded, configStale)
+	metrics.Registry.MustRegister(updates, updateErrors, configLoaded, configStale, conversionErrors)
 }
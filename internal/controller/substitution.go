@@ -0,0 +1,213 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var (
+	nodeVariableRegex    = regexp.MustCompile(`\$\{([^}]*)\}`)
+	nodeMapVariableRegex = regexp.MustCompile(`^node\.(labels|annotations)\[(?:'([^']*)'|"([^"]*)")\]$`)
+)
+
+// substituteNodeVariables returns a copy of the given configurations where the
+// node variables (i.e. ${node.name}) are replaced with the values of the given node.
+func substituteNodeVariables(cfgs []v1beta1.FRRConfiguration, node *corev1.Node) ([]v1beta1.FRRConfiguration, error) {
+	res := make([]v1beta1.FRRConfiguration, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		toSubstitute := cfg.DeepCopy()
+		err := substituteConfig(toSubstitute, node)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve the node variables of FRRConfiguration %s/%s for node %s: %w", cfg.Namespace, cfg.Name, node.Name, err)
+		}
+		res = append(res, *toSubstitute)
+	}
+	return res, nil
+}
+
+// substituteConfig replaces the node variables in the fields of the given configuration. As
+// the values of the labels and of the annotations are not validated by the api server, the
+// substituted fields are checked to hold a value valid for them.
+func substituteConfig(cfg *v1beta1.FRRConfiguration, node *corev1.Node) error {
+	var err error
+	s := func(value string, fldPath *field.Path, validate func(string) error) string {
+		if err != nil {
+			return value
+		}
+		substituted, substErr := substituteString(value, node)
+		if substErr != nil {
+			err = atField(fldPath, substErr)
+			return value
+		}
+		if substituted == value || validate == nil {
+			return substituted
+		}
+		if validErr := validate(substituted); validErr != nil {
+			err = invalidField(fldPath, "%s resolved to an invalid value: %w", value, validErr)
+			return value
+		}
+		return substituted
+	}
+	list := func(values []string, fldPath *field.Path, validate func(string) error) {
+		for i := range values {
+			values[i] = s(values[i], fldPath.Index(i), validate)
+		}
+	}
+
+	routersPath := field.NewPath("spec", "bgp", "routers")
+	for i := range cfg.Spec.BGP.Routers {
+		r := &cfg.Spec.BGP.Routers[i]
+		routerPath := routersPath.Index(i)
+		if r.ASNFrom != "" {
+			asn, asnErr := asnFromNode(r.ASN, r.ASNFrom, node)
+			if asnErr != nil {
+				return fmt.Errorf("router with vrf %q: %w", r.VRF, asnErr)
+			}
+			r.ASN = asn
+			r.ASNFrom = ""
+		}
+		r.ID = s(r.ID, routerPath.Child("id"), validateRouterID)
+		r.VRF = s(r.VRF, routerPath.Child("vrf"), validateVRFName)
+		list(r.Prefixes, routerPath.Child("prefixes"), validateCIDR)
+		for j := range r.ConditionalPrefixes {
+			c := &r.ConditionalPrefixes[j]
+			conditionalPath := routerPath.Child("conditionalPrefixes").Index(j)
+			list(c.Prefixes, conditionalPath.Child("prefixes"), validateCIDR)
+			probePath := conditionalPath.Child("condition", "probe")
+			if probe := c.Condition.Probe; probe != nil && probe.HTTPGet != nil {
+				probe.HTTPGet.Host = s(probe.HTTPGet.Host, probePath.Child("httpGet", "host"), validateHost)
+			}
+			if probe := c.Condition.Probe; probe != nil && probe.TCPSocket != nil {
+				probe.TCPSocket.Host = s(probe.TCPSocket.Host, probePath.Child("tcpSocket", "host"), validateHost)
+			}
+		}
+		for j := range r.Neighbors {
+			n := &r.Neighbors[j]
+			neighborPath := routerPath.Child("neighbors").Index(j)
+			if n.ASNFrom != "" {
+				asn, asnErr := asnFromNode(n.ASN, n.ASNFrom, node)
+				if asnErr != nil {
+					return fmt.Errorf("neighbor %s: %w", n.Address, asnErr)
+				}
+				n.ASN = asn
+				n.ASNFrom = ""
+			}
+			n.Address = s(n.Address, neighborPath.Child("address"), validateIP)
+			toAdvertisePath := neighborPath.Child("toAdvertise")
+			list(n.ToAdvertise.Allowed.Prefixes, toAdvertisePath.Child("allowed", "prefixes"), validateCIDR)
+			for k := range n.ToAdvertise.PrefixesWithCommunity {
+				n.ToAdvertise.PrefixesWithCommunity[k].Community = s(n.ToAdvertise.PrefixesWithCommunity[k].Community,
+					toAdvertisePath.Child("prefixesWithCommunity").Index(k).Child("community"), validateCommunity)
+			}
+		}
+	}
+
+	// The raw snippets are free form, the values of the variables are only
+	// required not to span multiple lines, which substituteString checks.
+	rawPath := field.NewPath("spec", "raw")
+	cfg.Spec.Raw.Config = s(cfg.Spec.Raw.Config, rawPath.Child("rawConfig"), nil)
+	for i := range cfg.Spec.Raw.Scoped {
+		scoped := &cfg.Spec.Raw.Scoped[i]
+		scopedPath := rawPath.Child("scoped").Index(i)
+		scoped.VRF = s(scoped.VRF, scopedPath.Child("vrf"), validateVRFName)
+		scoped.Neighbor = s(scoped.Neighbor, scopedPath.Child("neighbor"), validateIP)
+		scoped.Config = s(scoped.Config, scopedPath.Child("rawConfig"), nil)
+	}
+
+	return err
+}
+
+func asnFromNode(asn uint32, expression string, node *corev1.Node) (uint32, error) {
+	if asn != 0 {
+		return 0, fmt.Errorf("asn %d and asnFrom %s are mutually exclusive", asn, expression)
+	}
+	value, err := substituteString(expression, node)
+	if err != nil {
+		return 0, err
+	}
+	res, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("asnFrom %s resolved to an invalid asn %q", expression, value)
+	}
+	return uint32(res), nil
+}
+
+// substituteString replaces all the node variables in the given string.
+func substituteString(value string, node *corev1.Node) (string, error) {
+	var err error
+	res := nodeVariableRegex.ReplaceAllStringFunc(value, func(match string) string {
+		if err != nil {
+			return match
+		}
+		variable := strings.TrimSpace(nodeVariableRegex.FindStringSubmatch(match)[1])
+		var resolved string
+		resolved, err = resolveNodeVariable(variable, node)
+		if err != nil {
+			return match
+		}
+		// A newline in a label or in an annotation would inject commands in the configuration.
+		if lineErr := validateSingleLine(resolved); lineErr != nil {
+			err = fmt.Errorf("variable %s resolved to an invalid value: %w", variable, lineErr)
+		}
+		return resolved
+	})
+	if err != nil {
+		return "", err
+	}
+	return res, nil
+}
+
+func resolveNodeVariable(variable string, node *corev1.Node) (string, error) {
+	switch variable {
+	case "node.name":
+		return node.Name, nil
+	case "node.internalIPv4":
+		return nodeInternalIP(node, false)
+	case "node.internalIPv6":
+		return nodeInternalIP(node, true)
+	}
+
+	matches := nodeMapVariableRegex.FindStringSubmatch(variable)
+	if matches == nil {
+		return "", fmt.Errorf("unknown variable %s", variable)
+	}
+	key := matches[2] + matches[3]
+	values := node.Labels
+	if matches[1] == "annotations" {
+		values = node.Annotations
+	}
+	res, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("variable %s: node %s has no %s %s", variable, node.Name, strings.TrimSuffix(matches[1], "s"), key)
+	}
+	return res, nil
+}
+
+func nodeInternalIP(node *corev1.Node, ipv6 bool) (string, error) {
+	for _, a := range node.Status.Addresses {
+		if a.Type != corev1.NodeInternalIP {
+			continue
+		}
+		ip := net.ParseIP(a.Address)
+		if ip == nil {
+			continue
+		}
+		if (ip.To4() == nil) == ipv6 {
+			return ip.String(), nil
+		}
+	}
+	family := "ipv4"
+	if ipv6 {
+		family = "ipv6"
+	}
+	return "", fmt.Errorf("node %s has no internal %s address", node.Name, family)
+}
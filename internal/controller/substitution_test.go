@@ -0,0 +1,301 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSubstituteNodeVariables(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   testNodeName,
+			Labels: map[string]string{"rack-asn": "64520", "tor-asn": "64600"},
+			Annotations: map[string]string{
+				"example.com/loopback": "10.0.0.5/32",
+				"example.com/injected": "10.0.0.5\nip route 0.0.0.0/0 192.0.2.99",
+				"example.com/spaced":   "foo bar",
+			},
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeHostName, Address: testNodeName},
+				{Type: v1.NodeInternalIP, Address: "fc00:f853:ccd:e793::3"},
+				{Type: v1.NodeInternalIP, Address: "172.18.0.3"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		cfg      v1beta1.FRRConfigurationSpec
+		expected v1beta1.FRRConfigurationSpec
+		err      string
+	}{
+		{
+			name: "no variables",
+			cfg: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{
+						{ASN: 65001, ID: "192.0.2.1", Prefixes: []string{"192.0.2.0/24"}},
+					},
+				},
+			},
+			expected: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{
+						{ASN: 65001, ID: "192.0.2.1", Prefixes: []string{"192.0.2.0/24"}},
+					},
+				},
+			},
+		},
+		{
+			name: "all the variables",
+			cfg: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{
+						{
+							ASNFrom:  "${node.labels['rack-asn']}",
+							ID:       "${node.internalIPv4}",
+							VRF:      "vrf-${node.name}",
+							Prefixes: []string{"${ node.annotations[\"example.com/loopback\"] }"},
+							Neighbors: []v1beta1.Neighbor{
+								{
+									ASNFrom: "${node.labels['tor-asn']}",
+									Address: "fc00:f853:ccd:e793::1",
+									ToAdvertise: v1beta1.Advertise{
+										Allowed: v1beta1.AllowedOutPrefixes{
+											Prefixes: []string{"${node.annotations['example.com/loopback']}"},
+										},
+										PrefixesWithCommunity: []v1beta1.CommunityPrefixes{
+											{
+												Prefixes:  []string{"10.0.0.5/32"},
+												Community: "${node.labels['rack-asn']}:100",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				Raw: v1beta1.RawConfig{
+					Config: "! ${node.name} ${node.internalIPv6}",
+					Scoped: []v1beta1.ScopedRawConfig{
+						{
+							ASN:      64520,
+							VRF:      "vrf-${node.name}",
+							Neighbor: "${node.internalIPv4}",
+							Config:   "neighbor ${node.internalIPv4} description ${node.name}",
+						},
+					},
+				},
+			},
+			expected: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{
+						{
+							ASN:      64520,
+							ID:       "172.18.0.3",
+							VRF:      "vrf-testnode",
+							Prefixes: []string{"10.0.0.5/32"},
+							Neighbors: []v1beta1.Neighbor{
+								{
+									ASN:     64600,
+									Address: "fc00:f853:ccd:e793::1",
+									ToAdvertise: v1beta1.Advertise{
+										Allowed: v1beta1.AllowedOutPrefixes{
+											Prefixes: []string{"10.0.0.5/32"},
+										},
+										PrefixesWithCommunity: []v1beta1.CommunityPrefixes{
+											{
+												Prefixes:  []string{"10.0.0.5/32"},
+												Community: "64520:100",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				Raw: v1beta1.RawConfig{
+					Config: "! testnode fc00:f853:ccd:e793::3",
+					Scoped: []v1beta1.ScopedRawConfig{
+						{
+							ASN:      64520,
+							VRF:      "vrf-testnode",
+							Neighbor: "172.18.0.3",
+							Config:   "neighbor 172.18.0.3 description testnode",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "missing label",
+			cfg: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{
+						{ASN: 65001, VRF: "${node.labels['vrf']}"},
+					},
+				},
+			},
+			err: "node testnode has no label vrf",
+		},
+		{
+			name: "unknown variable",
+			cfg: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{
+						{ASN: 65001, ID: "${node.externalIPv4}"},
+					},
+				},
+			},
+			err: "unknown variable node.externalIPv4",
+		},
+		{
+			name: "invalid asn",
+			cfg: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{
+						{ASNFrom: "${node.name}"},
+					},
+				},
+			},
+			err: "resolved to an invalid asn",
+		},
+		{
+			name: "newline in the raw config",
+			cfg: v1beta1.FRRConfigurationSpec{
+				Raw: v1beta1.RawConfig{
+					Config: "! ${node.annotations['example.com/injected']}",
+				},
+			},
+			err: "spec.raw.rawConfig: variable node.annotations['example.com/injected'] resolved to an invalid value",
+		},
+		{
+			name: "newline in a scoped raw config",
+			cfg: v1beta1.FRRConfigurationSpec{
+				Raw: v1beta1.RawConfig{
+					Scoped: []v1beta1.ScopedRawConfig{
+						{ASN: 65001, Config: "neighbor ${node.annotations['example.com/injected']} description foo"},
+					},
+				},
+			},
+			err: "spec.raw.scoped[0].rawConfig: variable node.annotations['example.com/injected'] resolved to an invalid value",
+		},
+		{
+			name: "invalid router id",
+			cfg: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{
+						{ASN: 65001, ID: "${node.name}"},
+					},
+				},
+			},
+			err: "spec.bgp.routers[0].id: ${node.name} resolved to an invalid value",
+		},
+		{
+			name: "invalid vrf",
+			cfg: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{
+						{ASN: 65001, VRF: "${node.annotations['example.com/spaced']}"},
+					},
+				},
+			},
+			err: "spec.bgp.routers[0].vrf",
+		},
+		{
+			name: "invalid neighbor address",
+			cfg: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{
+						{
+							ASN: 65001,
+							Neighbors: []v1beta1.Neighbor{
+								{ASN: 65002, Address: "${node.annotations['example.com/loopback']}"},
+							},
+						},
+					},
+				},
+			},
+			err: "spec.bgp.routers[0].neighbors[0].address",
+		},
+		{
+			name: "invalid community",
+			cfg: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{
+						{
+							ASN: 65001,
+							Neighbors: []v1beta1.Neighbor{
+								{
+									ASN:     65002,
+									Address: "192.0.2.2",
+									ToAdvertise: v1beta1.Advertise{
+										PrefixesWithCommunity: []v1beta1.CommunityPrefixes{
+											{Prefixes: []string{"10.0.0.5/32"}, Community: "${node.name}"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			err: "spec.bgp.routers[0].neighbors[0].toAdvertise.prefixesWithCommunity[0].community",
+		},
+		{
+			name: "invalid scoped neighbor",
+			cfg: v1beta1.FRRConfigurationSpec{
+				Raw: v1beta1.RawConfig{
+					Scoped: []v1beta1.ScopedRawConfig{
+						{ASN: 65001, Neighbor: "${node.name}", Config: "neighbor 192.0.2.2 description foo"},
+					},
+				},
+			},
+			err: "spec.raw.scoped[0].neighbor",
+		},
+		{
+			name: "both asn and asnFrom",
+			cfg: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{
+						{ASN: 65001, ASNFrom: "${node.labels['rack-asn']}"},
+					},
+				},
+			},
+			err: "mutually exclusive",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfgs := []v1beta1.FRRConfiguration{{Spec: test.cfg}}
+			res, err := substituteNodeVariables(cfgs, node)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if diff := cmp.Diff(test.expected, res[0].Spec); diff != "" {
+				t.Fatalf("config different from expected: %s", diff)
+			}
+			if diff := cmp.Diff(test.cfg, cfgs[0].Spec); diff != "" {
+				t.Fatalf("original config was modified: %s", diff)
+			}
+		})
+	}
+}
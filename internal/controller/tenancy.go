@@ -0,0 +1,180 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"net"
+
+	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// defaultVRFName is the name the default vrf is referred to with in the tenant policies.
+const defaultVRFName = "default"
+
+// tenantAllowance is the combination of the tenant policies a namespace is bound to.
+// A nil field means the corresponding resource is not restricted.
+type tenantAllowance struct {
+	vrfs          sets.Set[string]
+	neighborCIDRs []*net.IPNet
+	prefixes      []*net.IPNet
+}
+
+// allowanceFor returns the allowance of the given namespace, or nil if the namespace
+// is not bound to any tenant.
+func allowanceFor(spec *v1beta1.FRRPolicySpec, namespace string) *tenantAllowance {
+	if spec == nil {
+		return nil
+	}
+
+	// A resource is restricted as soon as one of the tenants restricts it, and it can take
+	// any of the values allowed by the tenants.
+	var res *tenantAllowance
+	vrfsRestricted, neighborsRestricted, prefixesRestricted := false, false, false
+	for _, t := range spec.Tenants {
+		if !sets.New(t.Namespaces...).Has(namespace) {
+			continue
+		}
+		if res == nil {
+			res = &tenantAllowance{vrfs: sets.New[string]()}
+		}
+		vrfsRestricted = vrfsRestricted || len(t.VRFs) > 0
+		neighborsRestricted = neighborsRestricted || len(t.NeighborCIDRs) > 0
+		prefixesRestricted = prefixesRestricted || len(t.Prefixes) > 0
+		res.vrfs.Insert(t.VRFs...)
+		res.neighborCIDRs = append(res.neighborCIDRs, parseTenantCIDRs(t.NeighborCIDRs)...)
+		res.prefixes = append(res.prefixes, parseTenantCIDRs(t.Prefixes)...)
+	}
+	if res == nil {
+		return nil
+	}
+	if !vrfsRestricted {
+		res.vrfs = nil
+	}
+	if !neighborsRestricted {
+		res.neighborCIDRs = nil
+	}
+	if !prefixesRestricted {
+		res.prefixes = nil
+	}
+	return res
+}
+
+// parseTenantCIDRs parses the given cidrs, skipping the invalid ones as they are
+// rejected by the api server.
+func parseTenantCIDRs(cidrs []v1beta1.CIDR) []*net.IPNet {
+	res := []*net.IPNet{}
+	for _, c := range cidrs {
+		_, cidr, err := net.ParseCIDR(string(c))
+		if err != nil {
+			continue
+		}
+		res = append(res, cidr)
+	}
+	return res
+}
+
+// tenancyAllowed checks that the given configuration uses only the resources allowed by
+// the tenant policies its namespace is bound to. The configuration is expected to have
+// its node variables already resolved.
+func tenancyAllowed(spec *v1beta1.FRRPolicySpec, cfg v1beta1.FRRConfiguration) error {
+	allowance := allowanceFor(spec, cfg.Namespace)
+	if allowance == nil {
+		return nil
+	}
+
+	// The raw configuration can contain any command, so it can't be restricted to the
+	// resources of the tenant.
+	rawPath := field.NewPath("spec", "raw")
+	if cfg.Spec.Raw.Config != "" {
+		return newConversionError(ReasonForbidden, rawPath.Child("rawConfig"),
+			"raw configuration is not allowed for namespace %s, as it is bound to a tenant", cfg.Namespace)
+	}
+	if len(cfg.Spec.Raw.Scoped) > 0 {
+		return newConversionError(ReasonForbidden, rawPath.Child("scoped"),
+			"scoped raw configuration is not allowed for namespace %s, as it is bound to a tenant", cfg.Namespace)
+	}
+
+	routersPath := field.NewPath("spec", "bgp", "routers")
+	for i, r := range cfg.Spec.BGP.Routers {
+		routerPath := routersPath.Index(i)
+		vrf := r.VRF
+		if vrf == "" {
+			vrf = defaultVRFName
+		}
+		if allowance.vrfs != nil && !allowance.vrfs.Has(vrf) {
+			return newConversionError(ReasonForbidden, routerPath.Child("vrf"),
+				"vrf %s is not allowed for namespace %s by the tenancy policy", vrf, cfg.Namespace)
+		}
+		// The ips of the selected services and the pod cidrs of the node are not known when
+		// validating the configuration, so they can't be checked against the allowed prefixes.
+		if allowance.prefixes != nil && r.ServiceSelector != nil {
+			return newConversionError(ReasonForbidden, routerPath.Child("serviceSelector"),
+				"advertising services is not allowed for namespace %s, as the tenancy policy restricts its prefixes", cfg.Namespace)
+		}
+		if allowance.prefixes != nil && r.AdvertisePodCIDRs {
+			return newConversionError(ReasonForbidden, routerPath.Child("advertisePodCIDRs"),
+				"advertising the pod cidrs is not allowed for namespace %s, as the tenancy policy restricts its prefixes", cfg.Namespace)
+		}
+		for j, p := range r.Prefixes {
+			if !prefixAllowed(p, allowance.prefixes) {
+				return newConversionError(ReasonForbidden, routerPath.Child("prefixes").Index(j),
+					"prefix %s is not allowed for namespace %s by the tenancy policy", p, cfg.Namespace)
+			}
+		}
+		for j, c := range r.ConditionalPrefixes {
+			for k, p := range c.Prefixes {
+				if !prefixAllowed(p, allowance.prefixes) {
+					return newConversionError(ReasonForbidden, routerPath.Child("conditionalPrefixes").Index(j).Child("prefixes").Index(k),
+						"prefix %s is not allowed for namespace %s by the tenancy policy", p, cfg.Namespace)
+				}
+			}
+		}
+		for j, n := range r.Neighbors {
+			if !neighborAllowed(n.Address, allowance.neighborCIDRs) {
+				return newConversionError(ReasonForbidden, routerPath.Child("neighbors").Index(j).Child("address"),
+					"neighbor %s is not allowed for namespace %s by the tenancy policy", n.Address, cfg.Namespace)
+			}
+		}
+	}
+	return nil
+}
+
+// prefixAllowed tells if the given prefix is contained in any of the allowed cidrs.
+// Invalid prefixes are allowed, as they are reported when converting the configurations.
+func prefixAllowed(prefix string, allowed []*net.IPNet) bool {
+	if allowed == nil {
+		return true
+	}
+	_, cidr, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return true
+	}
+	ones, bits := cidr.Mask.Size()
+	for _, a := range allowed {
+		allowedOnes, allowedBits := a.Mask.Size()
+		if bits == allowedBits && ones >= allowedOnes && a.Contains(cidr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// neighborAllowed tells if the given address belongs to any of the allowed cidrs.
+// Invalid addresses are allowed, as they are reported when converting the configurations.
+func neighborAllowed(address string, allowed []*net.IPNet) bool {
+	if allowed == nil {
+		return true
+	}
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return true
+	}
+	for _, a := range allowed {
+		if a.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigsAllowedByTenancy(t *testing.T) {
+	config := func(namespace, name string, r v1beta1.Router) v1beta1.FRRConfiguration {
+		return v1beta1.FRRConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{r},
+				},
+			},
+		}
+	}
+	withRaw := func(cfg v1beta1.FRRConfiguration, raw v1beta1.RawConfig) v1beta1.FRRConfiguration {
+		cfg.Spec.Raw = raw
+		return cfg
+	}
+	policy := &v1beta1.FRRPolicySpec{
+		Tenants: []v1beta1.TenantPolicy{
+			{
+				Namespaces:    []string{"tenant-a"},
+				VRFs:          []string{"red"},
+				NeighborCIDRs: []v1beta1.CIDR{"192.0.2.0/24"},
+				Prefixes:      []v1beta1.CIDR{"198.51.100.0/24"},
+			},
+			{
+				Namespaces: []string{"tenant-a", "tenant-b"},
+				VRFs:       []string{"default"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		cfgs            []v1beta1.FRRConfiguration
+		expectedAllowed []string
+		expectedIgnored []string
+	}{
+		{
+			name: "not bound namespace",
+			cfgs: []v1beta1.FRRConfiguration{
+				config("other", "cfg", v1beta1.Router{ASN: 65001, VRF: "blue", Prefixes: []string{"203.0.113.0/24"}}),
+			},
+			expectedAllowed: []string{"cfg"},
+		},
+		{
+			name: "allowed",
+			cfgs: []v1beta1.FRRConfiguration{
+				config("tenant-a", "cfg", v1beta1.Router{
+					ASN:      65001,
+					VRF:      "red",
+					Prefixes: []string{"198.51.100.0/25"},
+					Neighbors: []v1beta1.Neighbor{
+						{ASN: 65002, Address: "192.0.2.2"},
+					},
+				}),
+			},
+			expectedAllowed: []string{"cfg"},
+		},
+		{
+			name: "vrfs of multiple tenants",
+			cfgs: []v1beta1.FRRConfiguration{
+				config("tenant-a", "cfg", v1beta1.Router{ASN: 65001}),
+				config("tenant-b", "cfg1", v1beta1.Router{ASN: 65001, VRF: "red"}),
+			},
+			expectedAllowed: []string{"cfg"},
+			expectedIgnored: []string{"tenant-b/cfg1: spec.bgp.routers[0].vrf: vrf red is not allowed for namespace tenant-b by the tenancy policy"},
+		},
+		{
+			name: "prefix not allowed",
+			cfgs: []v1beta1.FRRConfiguration{
+				config("tenant-a", "cfg", v1beta1.Router{ASN: 65001, VRF: "red", Prefixes: []string{"198.51.100.0/23"}}),
+			},
+			expectedAllowed: []string{},
+			expectedIgnored: []string{"tenant-a/cfg: spec.bgp.routers[0].prefixes[0]: prefix 198.51.100.0/23 is not allowed for namespace tenant-a by the tenancy policy"},
+		},
+		{
+			name: "neighbor not allowed",
+			cfgs: []v1beta1.FRRConfiguration{
+				config("tenant-a", "cfg", v1beta1.Router{
+					ASN: 65001,
+					VRF: "red",
+					Neighbors: []v1beta1.Neighbor{
+						{ASN: 65002, Address: "203.0.113.2"},
+					},
+				}),
+			},
+			expectedAllowed: []string{},
+			expectedIgnored: []string{"tenant-a/cfg: spec.bgp.routers[0].neighbors[0].address: neighbor 203.0.113.2 is not allowed for namespace tenant-a by the tenancy policy"},
+		},
+		{
+			name: "service selector with restricted prefixes",
+			cfgs: []v1beta1.FRRConfiguration{
+				config("tenant-a", "cfg", v1beta1.Router{ASN: 65001, VRF: "red", ServiceSelector: &metav1.LabelSelector{}}),
+			},
+			expectedAllowed: []string{},
+			expectedIgnored: []string{"tenant-a/cfg: spec.bgp.routers[0].serviceSelector: advertising services is not allowed for namespace tenant-a, as the tenancy policy restricts its prefixes"},
+		},
+		{
+			name: "pod cidrs with restricted prefixes",
+			cfgs: []v1beta1.FRRConfiguration{
+				config("tenant-a", "cfg", v1beta1.Router{ASN: 65001, VRF: "red", AdvertisePodCIDRs: true}),
+			},
+			expectedAllowed: []string{},
+			expectedIgnored: []string{"tenant-a/cfg: spec.bgp.routers[0].advertisePodCIDRs: advertising the pod cidrs is not allowed for namespace tenant-a, as the tenancy policy restricts its prefixes"},
+		},
+		{
+			name: "raw config",
+			cfgs: []v1beta1.FRRConfiguration{
+				withRaw(config("tenant-b", "cfg", v1beta1.Router{ASN: 65001}), v1beta1.RawConfig{Config: "ip route 0.0.0.0/0 192.0.2.1"}),
+				withRaw(config("other", "cfg1", v1beta1.Router{ASN: 65001}), v1beta1.RawConfig{Config: "ip route 0.0.0.0/0 192.0.2.1"}),
+			},
+			expectedAllowed: []string{"cfg1"},
+			expectedIgnored: []string{"tenant-b/cfg: spec.raw.rawConfig: raw configuration is not allowed for namespace tenant-b, as it is bound to a tenant"},
+		},
+		{
+			name: "scoped raw config",
+			cfgs: []v1beta1.FRRConfiguration{
+				withRaw(config("tenant-b", "cfg", v1beta1.Router{ASN: 65001}), v1beta1.RawConfig{
+					Scoped: []v1beta1.ScopedRawConfig{{ASN: 65001, VRF: "blue", Config: "neighbor 203.0.113.2 remote-as 65002"}},
+				}),
+			},
+			expectedAllowed: []string{},
+			expectedIgnored: []string{"tenant-b/cfg: spec.raw.scoped: scoped raw configuration is not allowed for namespace tenant-b, as it is bound to a tenant"},
+		},
+		{
+			name: "service selector and pod cidrs with unrestricted prefixes",
+			cfgs: []v1beta1.FRRConfiguration{
+				config("tenant-b", "cfg", v1beta1.Router{ASN: 65001, ServiceSelector: &metav1.LabelSelector{}, AdvertisePodCIDRs: true}),
+			},
+			expectedAllowed: []string{"cfg"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			allowed, ignored := configsAllowedByPolicy(test.cfgs, policy)
+			allowedNames := []string{}
+			for _, cfg := range allowed {
+				allowedNames = append(allowedNames, cfg.Name)
+			}
+			if diff := cmp.Diff(test.expectedAllowed, allowedNames); diff != "" {
+				t.Fatalf("allowed configs different from expected: %s", diff)
+			}
+			if diff := cmp.Diff(test.expectedIgnored, ignored); diff != "" {
+				t.Fatalf("ignored configs different from expected: %s", diff)
+			}
+		})
+	}
+}
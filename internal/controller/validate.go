@@ -6,34 +6,76 @@ import (
 	"net"
 
 	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
+	"github.com/metallb/frr-k8s/internal/frr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// TransientError is an error that happens due to interdependencies
-// between crds, such as referencing non-existing secrets.
-// Since we don't want webhooks to make assumptions on ordering, we reset the
-// fields that could cause a transient error from configurations before validating them.
-type TransientError struct {
-	Message string
+// Validate checks that the given configurations can be converted to a valid FRR configuration.
+// It returns the conflicts between the configurations that are resolved by their priority as warnings.
+func Validate(resources ...client.ObjectList) ([]string, error) {
+	return validate(nil, resources...)
 }
 
-func (e TransientError) Error() string { return e.Message }
+// ValidateWithRawConfigCheck returns a validation function that, in addition to what Validate
+// does, checks the FRR configuration rendered for each node with the given function when
+// any of the configurations contains a raw config.
+func ValidateWithRawConfigCheck(check func(*frr.Config) error) func(resources ...client.ObjectList) ([]string, error) {
+	return func(resources ...client.ObjectList) ([]string, error) {
+		return validate(check, resources...)
+	}
+}
 
-func Validate(resources ...client.ObjectList) error {
+func validate(checkRaw func(*frr.Config) error, resources ...client.ObjectList) ([]string, error) {
 	clusterResources := ClusterResources{
 		FRRConfigs: make([]v1beta1.FRRConfiguration, 0),
 	}
 
+	nodes := []corev1.Node{}
 	for _, list := range resources {
-		if l, ok := list.(*v1beta1.FRRConfigurationList); ok {
+		switch l := list.(type) {
+		case *v1beta1.FRRConfigurationList:
 			clusterResources.FRRConfigs = append(clusterResources.FRRConfigs, l.Items...)
+		case *corev1.NodeList:
+			nodes = append(nodes, l.Items...)
+		case *v1beta1.FRRDefaultsList:
+			clusterResources.Defaults = defaultsSpec(l.Items)
+		case *v1beta1.FRRPolicyList:
+			clusterResources.Policy = policySpec(l.Items)
 		}
 	}
 	resetSecrets(clusterResources.FRRConfigs)
 
-	_, err := apiToFRR(clusterResources, []net.IPNet{})
-	return err
+	// The configurations not allowed by the cluster policy are ignored, as they are on the nodes.
+	if len(nodes) == 0 {
+		clusterResources.FRRConfigs, _ = configsAllowedByPolicy(clusterResources.FRRConfigs, clusterResources.Policy)
+		config, warnings, err := apiToFRR(clusterResources, []net.IPNet{})
+		if err != nil {
+			return nil, err
+		}
+		return warnings, checkRawConfig(checkRaw, config, clusterResources.FRRConfigs)
+	}
+
+	// The node variables are resolved against each of the nodes the configurations apply to.
+	warnings := []string{}
+	for i := range nodes {
+		cfgs, err := substituteNodeVariables(clusterResources.FRRConfigs, &nodes[i])
+		if err != nil {
+			return nil, err
+		}
+		cfgs, _ = configsAllowedByPolicy(cfgs, clusterResources.Policy)
+		config, nodeWarnings, err := apiToFRR(ClusterResources{FRRConfigs: cfgs, Defaults: clusterResources.Defaults, Policy: clusterResources.Policy}, []net.IPNet{})
+		if err != nil {
+			return nil, err
+		}
+		if err := checkRawConfig(checkRaw, config, cfgs); err != nil {
+			return nil, err
+		}
+		warnings = appendMissing(warnings, nodeWarnings...)
+	}
+	return warnings, nil
 }
 
 // Resets the secrets fields of the given configurations as they can cause a transient error.
@@ -46,3 +88,44 @@ func resetSecrets(cfgs []v1beta1.FRRConfiguration) {
 		}
 	}
 }
+
+// checkRawConfig checks the given rendered configuration with the given function, if
+// any of the configurations it comes from contains a raw config, either appended or scoped.
+// When only one of them does, the error is related to it, down to the snippet when there
+// is only one.
+func checkRawConfig(check func(*frr.Config) error, config *frr.Config, cfgs []v1beta1.FRRConfiguration) error {
+	if check == nil {
+		return nil
+	}
+	var withRaw []types.NamespacedName
+	var snippets []*field.Path
+	for _, cfg := range cfgs {
+		if cfg.Spec.Raw.Config == "" && len(cfg.Spec.Raw.Scoped) == 0 {
+			continue
+		}
+		withRaw = append(withRaw, types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name})
+		if cfg.Spec.Raw.Config != "" {
+			snippets = append(snippets, field.NewPath("spec", "raw", "rawConfig"))
+		}
+		for i := range cfg.Spec.Raw.Scoped {
+			snippets = append(snippets, field.NewPath("spec", "raw", "scoped").Index(i).Child("rawConfig"))
+		}
+	}
+	if len(withRaw) == 0 {
+		return nil
+	}
+
+	err := check(config)
+	if err == nil {
+		return nil
+	}
+	res := &ConversionError{Reason: ReasonInvalid, Err: err}
+	if len(withRaw) == 1 {
+		res.Object = withRaw[0]
+		res.Field = field.NewPath("spec", "raw")
+		if len(snippets) == 1 {
+			res.Field = snippets[0]
+		}
+	}
+	return res
+}
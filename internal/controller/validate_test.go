@@ -0,0 +1,117 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	v1beta1 "github.com/metallb/frr-k8s/api/v1beta1"
+	"github.com/metallb/frr-k8s/internal/frr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateRawConfigCheck(t *testing.T) {
+	config := func(name string, raw v1beta1.RawConfig) v1beta1.FRRConfiguration {
+		return v1beta1.FRRConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.FRRConfigurationSpec{
+				BGP: v1beta1.BGPConfig{
+					Routers: []v1beta1.Router{{ASN: 65001}},
+				},
+				Raw: raw,
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		configs        []v1beta1.FRRConfiguration
+		mustCheck      bool
+		expectedObject string
+		expectedField  string
+	}{
+		{
+			name:    "no raw config",
+			configs: []v1beta1.FRRConfiguration{config("test", v1beta1.RawConfig{})},
+		},
+		{
+			name:           "raw config",
+			configs:        []v1beta1.FRRConfiguration{config("test", v1beta1.RawConfig{Config: "foo"})},
+			mustCheck:      true,
+			expectedObject: "default/test",
+			expectedField:  "spec.raw.rawConfig",
+		},
+		{
+			name: "scoped raw config",
+			configs: []v1beta1.FRRConfiguration{config("test", v1beta1.RawConfig{
+				Scoped: []v1beta1.ScopedRawConfig{{ASN: 65001, Config: "foo"}},
+			})},
+			mustCheck:      true,
+			expectedObject: "default/test",
+			expectedField:  "spec.raw.scoped[0].rawConfig",
+		},
+		{
+			name: "raw config and scoped raw config",
+			configs: []v1beta1.FRRConfiguration{config("test", v1beta1.RawConfig{
+				Config: "foo",
+				Scoped: []v1beta1.ScopedRawConfig{{ASN: 65001, Config: "bar"}},
+			})},
+			mustCheck:      true,
+			expectedObject: "default/test",
+			expectedField:  "spec.raw",
+		},
+		{
+			name: "raw configs in different configurations",
+			configs: []v1beta1.FRRConfiguration{
+				config("test", v1beta1.RawConfig{Config: "foo"}),
+				config("other", v1beta1.RawConfig{
+					Scoped: []v1beta1.ScopedRawConfig{{ASN: 65001, Config: "bar"}},
+				}),
+			},
+			mustCheck: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checked := false
+			validate := ValidateWithRawConfigCheck(func(*frr.Config) error {
+				checked = true
+				return errors.New("invalid")
+			})
+			_, err := validate(&v1beta1.FRRConfigurationList{Items: test.configs})
+			if checked != test.mustCheck {
+				t.Fatalf("expected checked to be %v, got %v", test.mustCheck, checked)
+			}
+			if !test.mustCheck {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			var convErr *ConversionError
+			if !errors.As(err, &convErr) {
+				t.Fatalf("expected a conversion error, got %v", err)
+			}
+			object := ""
+			if convErr.Object.Name != "" {
+				object = convErr.Object.String()
+			}
+			if object != test.expectedObject {
+				t.Fatalf("expected object %q, got %q", test.expectedObject, object)
+			}
+			field := ""
+			if convErr.Field != nil {
+				field = convErr.Field.String()
+			}
+			if field != test.expectedField {
+				t.Fatalf("expected field %q, got %q", test.expectedField, field)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// checkHostname is the hostname used when rendering a configuration only to check it.
+const checkHostname = "frr-k8s-check"
+
+var passwordRegexp = regexp.MustCompile(`password.*`)
+
+// checkConfigFile runs FRR's parser against the given file, without applying it
+// to any running daemon.
+var checkConfigFile = func(fileName string) ([]byte, error) {
+	return exec.Command("vtysh", "--dryrun", "--inputfile", fileName).CombinedOutput()
+}
+
+// CheckConfig renders the given configuration and verifies it is accepted by
+// FRR's parser. This requires the FRR binaries to be available.
+func CheckConfig(config *Config) error {
+	toCheck := *config
+	if toCheck.Hostname == "" {
+		toCheck.Hostname = checkHostname
+	}
+	if toCheck.Loglevel == "" {
+		toCheck.Loglevel = LogLevelToFRR("")
+	}
+
+	configString, err := templateConfig(&toCheck)
+	if err != nil {
+		return fmt.Errorf("failed to render the configuration: %w", err)
+	}
+
+	f, err := os.CreateTemp("", "frr-check-*.conf")
+	if err != nil {
+		return fmt.Errorf("failed to create the configuration file to check: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if _, err := f.WriteString(configString); err != nil {
+		return fmt.Errorf("failed to write the configuration file to check: %w", err)
+	}
+
+	out, err := checkConfigFile(f.Name())
+	if err != nil {
+		res := strings.TrimSpace(passwordRegexp.ReplaceAllString(string(out), "password <retracted>"))
+		if res == "" {
+			res = err.Error()
+		}
+		return fmt.Errorf("the configuration was rejected by FRR: %s", res)
+	}
+	return nil
+}
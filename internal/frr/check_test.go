@@ -0,0 +1,99 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckConfig(t *testing.T) {
+	config := &Config{
+		Routers: []*RouterConfig{
+			{
+				MyASN: 65000,
+				Neighbors: []*NeighborConfig{
+					{
+						IPFamily: "ipv4",
+						ASN:      65001,
+						Addr:     "192.168.1.2",
+						Password: "secret",
+					},
+				},
+			},
+		},
+		ExtraConfig: "foo bar",
+	}
+
+	tests := []struct {
+		name     string
+		output   string
+		err      error
+		expected string
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name:     "invalid",
+			output:   "line 10: % Unknown command: foo bar\n",
+			err:      errors.New("exit status 2"),
+			expected: "% Unknown command: foo bar",
+		},
+		{
+			name:     "invalid with password",
+			output:   "line 8: % Unknown command: neighbor 192.168.1.2 password secret foo\n",
+			err:      errors.New("exit status 2"),
+			expected: "neighbor 192.168.1.2 password <retracted>",
+		},
+		{
+			name:     "no output",
+			err:      errors.New("exit status 1"),
+			expected: "exit status 1",
+		},
+	}
+
+	oldCheck := checkConfigFile
+	defer func() { checkConfigFile = oldCheck }()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkConfigFile = func(fileName string) ([]byte, error) {
+				content, err := os.ReadFile(fileName)
+				if err != nil {
+					t.Fatalf("failed to read the file to check: %v", err)
+				}
+				if !strings.Contains(string(content), "hostname "+checkHostname) {
+					t.Fatalf("expecting the hostname to be set, got %s", content)
+				}
+				if !strings.Contains(string(content), "foo bar") {
+					t.Fatalf("expecting the raw config to be rendered, got %s", content)
+				}
+				return []byte(test.output), test.err
+			}
+
+			err := CheckConfig(config)
+			if test.expected == "" {
+				if err != nil {
+					t.Fatalf("not expecting error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expecting error, got nil")
+			}
+			if !strings.Contains(err.Error(), test.expected) {
+				t.Fatalf("expecting error to contain %q, got %q", test.expected, err)
+			}
+			if strings.Contains(err.Error(), "secret") {
+				t.Fatalf("expecting the password to be retracted, got %q", err)
+			}
+		})
+	}
+
+	if config.Hostname != "" {
+		t.Fatalf("expecting the checked config not to be modified")
+	}
+}
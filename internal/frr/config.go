@@ -10,19 +10,21 @@ import (
 	"os"
 	"reflect"
 	"strconv"
-	"syscall"
+	"strings"
 	"text/template"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/metallb/frr-k8s/internal/ipfamily"
+	"github.com/metallb/frr-k8s/internal/reloader"
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 var (
-	configFileName      = "/etc/frr_reloader/frr.conf"
-	reloaderPidFileName = "/etc/frr_reloader/reloader.pid"
+	configFileName     = "/etc/frr_reloader/frr.conf"
+	reloaderSocketName = "/etc/frr_reloader/reloader.sock"
 	//go:embed templates/* templates/*
 	templates embed.FS
 )
@@ -47,6 +49,25 @@ type RouterConfig struct {
 	VRF          string
 	IPV4Prefixes []string
 	IPV6Prefixes []string
+	// GracefulShutdown enables the BGP graceful shutdown (RFC 8326) for all
+	// the sessions of the router.
+	GracefulShutdown bool
+	// ASPathPrepend is the number of times the ASN of the router is prepended
+	// to the AS path of the advertised routes.
+	ASPathPrepend int
+	// RawConfig is the raw configuration injected in the router section.
+	RawConfig ScopedRawConfig
+}
+
+// ScopedRawConfig is the raw configuration injected in a given section
+// of the configuration.
+type ScopedRawConfig struct {
+	// Config is injected in the section itself.
+	Config string
+	// IPV4 is injected in the ipv4 unicast address family of the section.
+	IPV4 string
+	// IPV6 is injected in the ipv6 unicast address family of the section.
+	IPV6 string
 }
 
 type BFDProfile struct {
@@ -77,6 +98,11 @@ type NeighborConfig struct {
 	Incoming      AllowedIn
 	Outgoing      AllowedOut
 	AlwaysBlock   []IncomingFilter
+	// Disabled administratively shuts down the session.
+	Disabled        bool
+	DisabledMessage string
+	// RawConfig is the raw configuration injected in the sections related to the neighbor.
+	RawConfig ScopedRawConfig
 }
 
 func (n *NeighborConfig) ID() string {
@@ -144,6 +170,12 @@ type OutgoingFilter struct {
 	LocalPref        uint32
 }
 
+// RenderConfig returns the content of the FRR configuration file
+// rendered out of the given configuration.
+func RenderConfig(config *Config) (string, error) {
+	return templateConfig(config)
+}
+
 // templateConfig uses the template library to template
 // 'globalConfigTemplate' using 'data'.
 func templateConfig(data interface{}) (string, error) {
@@ -180,6 +212,13 @@ func templateConfig(data interface{}) (string, error) {
 			"deniedIncomingList": func(neighbor *NeighborConfig) string {
 				return fmt.Sprintf("%s-denied-inpl-%s", neighbor.ID(), neighbor.IPFamily)
 			},
+			"asPathPrepend": func(router *RouterConfig) string {
+				asns := make([]string, router.ASPathPrepend)
+				for i := range asns {
+					asns[i] = strconv.FormatUint(uint64(router.MyASN), 10)
+				}
+				return strings.Join(asns, " ")
+			},
 			"mustDisableConnectedCheck": func(ipFamily ipfamily.Family, myASN, asn uint32, eBGPMultiHop bool) bool {
 				// return true only for IPv6 eBGP sessions
 				if ipFamily == "ipv6" && myASN != asn && !eBGPMultiHop {
@@ -187,6 +226,7 @@ func templateConfig(data interface{}) (string, error) {
 				}
 				return false
 			},
+			"indent": indentRawConfig,
 			"dict": func(values ...interface{}) (map[string]interface{}, error) {
 				if len(values)%2 != 0 {
 					return nil, errors.New("invalid dict call, expecting even number of args")
@@ -211,43 +251,71 @@ func templateConfig(data interface{}) (string, error) {
 	return b.String(), err
 }
 
+// indentRawConfig indents the given raw config snippet by the given number of spaces,
+// replacing the indentation common to its lines, so that its lines are nested in the
+// section the snippet is injected in.
+func indentRawConfig(spaces int, snippet string) string {
+	lines := strings.Split(snippet, "\n")
+	common := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		lineIndent := len(l) - len(strings.TrimLeft(l, " \t"))
+		if common == -1 || lineIndent < common {
+			common = lineIndent
+		}
+	}
+	prefix := strings.Repeat(" ", spaces)
+	for i, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			lines[i] = ""
+			continue
+		}
+		lines[i] = prefix + l[common:]
+	}
+	return strings.Join(lines, "\n")
+}
+
 // writeConfigFile writes the FRR configuration file (represented as a string)
 // to 'filename'.
 func writeConfig(config string, filename string) error {
 	return os.WriteFile(filename, []byte(config), 0600)
 }
 
+// reloadTimeout is the maximum time to wait for the reloader to apply the configuration.
+var reloadTimeout = 5 * time.Minute
+
 // reloadConfig requests that FRR reloads the configuration file. This is
 // called after updating the configuration.
-var reloadConfig = func() error {
-	pidFile, found := os.LookupEnv("FRR_RELOADER_PID_FILE")
-	if found {
-		reloaderPidFileName = pidFile
-	}
+var reloadConfig = func() (reloader.Response, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), reloadTimeout)
+	defer cancel()
+	return reloaderClient().Reload(ctx)
+}
 
-	pid, err := os.ReadFile(reloaderPidFileName)
-	if err != nil {
-		return err
-	}
+// runningConfigTimeout is the maximum time to wait for the reloader to return the running configuration.
+var runningConfigTimeout = 10 * time.Second
 
-	pidInt, err := strconv.Atoi(string(pid))
-	if err != nil {
-		return err
-	}
+// fetchRunningConfig returns the configuration FRR is currently running with.
+var fetchRunningConfig = func() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), runningConfigTimeout)
+	defer cancel()
+	return reloaderClient().RunningConfig(ctx)
+}
 
-	// send HUP signal to FRR reloader
-	err = syscall.Kill(pidInt, syscall.SIGHUP)
-	if err != nil {
-		return err
+func reloaderClient() *reloader.Client {
+	socket, found := os.LookupEnv("FRR_RELOADER_SOCKET")
+	if found {
+		reloaderSocketName = socket
 	}
-
-	return nil
+	return reloader.NewClient(reloaderSocketName)
 }
 
 // generateAndReloadConfigFile takes a 'struct Config' and, using a template,
 // generates and writes a valid FRR configuration file. If this completes
 // successfully it will also force FRR to reload that configuration file.
-func generateAndReloadConfigFile(config *Config, l log.Logger) error {
+func generateAndReloadConfigFile(config *Config, l log.Logger) (reloader.Response, error) {
 	filename, found := os.LookupEnv("FRR_CONFIG_FILE")
 	if found {
 		configFileName = filename
@@ -256,34 +324,66 @@ func generateAndReloadConfigFile(config *Config, l log.Logger) error {
 	configString, err := templateConfig(config)
 	if err != nil {
 		level.Error(l).Log("op", "reload", "error", err, "cause", "template", "config", config)
-		return err
+		return reloader.Response{}, err
 	}
 	err = writeConfig(configString, configFileName)
 	if err != nil {
 		level.Error(l).Log("op", "reload", "error", err, "cause", "writeConfig", "config", config)
-		return err
+		return reloader.Response{}, err
 	}
 
-	err = reloadConfig()
+	res, err := reloadConfig()
 	if err != nil {
 		level.Error(l).Log("op", "reload", "error", err, "cause", "reload", "config", config)
-		return err
+		return res, err
 	}
-	return nil
+	if res.Result != reloader.ResultSuccess {
+		level.Error(l).Log("op", "reload", "result", res.Result, "error", res.Error, "stderr", res.Stderr, "duration", res.Duration)
+		return res, fmt.Errorf("reload failed: %s", res.Error)
+	}
+	level.Info(l).Log("op", "reload", "result", res.Result, "duration", res.Duration, "diff", res.Diff)
+	return res, nil
+}
+
+// retryBackoff computes the interval a failed reload is retried after, doubling
+// it at each consecutive failure up to a maximum, plus some jitter so that the
+// retries of many nodes don't happen in lockstep.
+type retryBackoff struct {
+	initial time.Duration
+	max     time.Duration
+	// jitter is the maximum fraction of the interval randomly added to it.
+	jitter float64
+}
+
+// interval returns the time to wait after the given number of consecutive failures.
+func (b retryBackoff) interval(failures int) time.Duration {
+	res := b.initial
+	for i := 1; i < failures && res < b.max; i++ {
+		res *= 2
+	}
+	if b.jitter > 0 {
+		res = wait.Jitter(res, b.jitter)
+	}
+	if b.max > 0 && res > b.max {
+		res = b.max
+	}
+	return res
 }
 
 // debouncer takes a function that processes an Config, a channel where
 // the update requests are sent, and squashes any requests coming in a given timeframe
-// as a single request.
+// as a single request. Failed requests are retried with the given backoff, until
+// they succeed or a new config is received.
 func debouncer(ctx context.Context, body func(config *Config) error,
 	reload <-chan reloadEvent,
 	reloadInterval time.Duration,
-	failureRetryInterval time.Duration,
+	backoff retryBackoff,
 	l log.Logger) {
 	go func() {
 		var config *Config
 		var timeOut <-chan time.Time
 		timerSet := false
+		failures := 0
 		for {
 			select {
 			case newCfg, ok := <-reload:
@@ -300,6 +400,13 @@ func debouncer(ctx context.Context, body func(config *Config) error,
 				}
 				if !newCfg.useOld {
 					config = newCfg.config
+					// A new config deserves a fresh attempt, instead of waiting
+					// for the backoff of the failing one to expire.
+					if failures > 0 {
+						failures = 0
+						reloadBackoff.Set(0)
+						timerSet = false
+					}
 				}
 				if !timerSet {
 					timeOut = time.After(reloadInterval)
@@ -308,10 +415,16 @@ func debouncer(ctx context.Context, body func(config *Config) error,
 			case <-timeOut:
 				err := body(config)
 				if err != nil {
-					timeOut = time.After(failureRetryInterval)
+					failures++
+					retryIn := backoff.interval(failures)
+					reloadBackoff.Set(retryIn.Seconds())
+					level.Debug(l).Log("op", "reload", "action", "retry", "failures", failures, "interval", retryIn)
+					timeOut = time.After(retryIn)
 					timerSet = true
 					continue
 				}
+				failures = 0
+				reloadBackoff.Set(0)
 				timerSet = false
 			case <-ctx.Done():
 				return
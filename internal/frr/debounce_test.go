@@ -23,7 +23,7 @@ func TestDebounce(t *testing.T) {
 
 	reload := make(chan reloadEvent)
 	defer close(reload)
-	debouncer(context.Background(), dummyUpdate, reload, timer, failureTimer, log.NewNopLogger())
+	debouncer(context.Background(), dummyUpdate, reload, timer, retryBackoff{initial: failureTimer, max: failureTimer}, log.NewNopLogger())
 	reload <- reloadEvent{config: &Config{Hostname: "1"}}
 	reload <- reloadEvent{config: &Config{Hostname: "2"}}
 	reload <- reloadEvent{config: &Config{Hostname: "3"}}
@@ -66,7 +66,7 @@ func TestDebounceRetry(t *testing.T) {
 
 	reload := make(chan reloadEvent)
 	defer close(reload)
-	debouncer(context.Background(), dummyUpdate, reload, timer, failureTimer, log.NewNopLogger())
+	debouncer(context.Background(), dummyUpdate, reload, timer, retryBackoff{initial: failureTimer, max: failureTimer}, log.NewNopLogger())
 
 	reload <- reloadEvent{config: &Config{Hostname: "1"}}
 	reload <- reloadEvent{config: &Config{Hostname: "2"}}
@@ -92,7 +92,7 @@ func TestDebounceReuseOld(t *testing.T) {
 
 	reload := make(chan reloadEvent)
 	defer close(reload)
-	debouncer(context.Background(), dummyUpdate, reload, timer, failureTimer, log.NewNopLogger())
+	debouncer(context.Background(), dummyUpdate, reload, timer, retryBackoff{initial: failureTimer, max: failureTimer}, log.NewNopLogger())
 
 	reload <- reloadEvent{config: &Config{Hostname: "1"}}
 	if len(result) != 0 {
@@ -127,7 +127,7 @@ func TestDebounceSameConfig(t *testing.T) {
 
 	reload := make(chan reloadEvent)
 	defer close(reload)
-	debouncer(context.Background(), dummyUpdate, reload, timer, failureTimer, log.NewNopLogger())
+	debouncer(context.Background(), dummyUpdate, reload, timer, retryBackoff{initial: failureTimer, max: failureTimer}, log.NewNopLogger())
 	reload <- reloadEvent{config: &Config{Hostname: "1"}}
 	reload <- reloadEvent{config: &Config{Hostname: "2"}}
 	reload <- reloadEvent{config: &Config{Hostname: "3", Routers: []*RouterConfig{{MyASN: 23}}}}
@@ -152,3 +152,61 @@ func TestDebounceSameConfig(t *testing.T) {
 		t.Fatalf("received extra updates: %d %s", len(result), updated.Hostname)
 	}
 }
+
+func TestDebounceBackoff(t *testing.T) {
+	attempts := make(chan time.Time, 10)
+	dummyUpdate := func(config *Config) error {
+		attempts <- time.Now()
+		return fmt.Errorf("error")
+	}
+
+	reload := make(chan reloadEvent)
+	defer close(reload)
+	debouncer(context.Background(), dummyUpdate, reload, timer, retryBackoff{initial: failureTimer, max: 4 * failureTimer}, log.NewNopLogger())
+
+	reload <- reloadEvent{config: &Config{Hostname: "1"}}
+	time.Sleep(20 * failureTimer)
+	// Retried after 10, 20, 40, 40... ms, instead of every 10ms.
+	if len(attempts) > 8 {
+		t.Fatalf("expecting the retries to back off, got %d attempts", len(attempts))
+	}
+	if len(attempts) < 3 {
+		t.Fatalf("expecting the reload to be retried, got %d attempts", len(attempts))
+	}
+
+	// A new config is attempted without waiting for the backoff.
+	for len(attempts) > 0 {
+		<-attempts
+	}
+	reload <- reloadEvent{config: &Config{Hostname: "2"}}
+	sent := time.Now()
+	select {
+	case attempt := <-attempts:
+		if attempt.Sub(sent) > 3*failureTimer {
+			t.Fatalf("expecting the new config to be applied after the debounce interval, took %s", attempt.Sub(sent))
+		}
+	case <-time.After(20 * failureTimer):
+		t.Fatalf("new config not applied")
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	backoff := retryBackoff{initial: time.Second, max: 10 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, e := range expected {
+		if got := backoff.interval(i + 1); got != e {
+			t.Fatalf("failure %d: expecting %s, got %s", i+1, e, got)
+		}
+	}
+
+	backoff.jitter = 0.5
+	for i := 1; i < 10; i++ {
+		got := backoff.interval(i)
+		if got > backoff.max {
+			t.Fatalf("failure %d: interval %s greater than the maximum", i, got)
+		}
+	}
+	if got := backoff.interval(1); got < time.Second || got > 1500*time.Millisecond {
+		t.Fatalf("expecting the jittered interval within [1s, 1.5s], got %s", got)
+	}
+}
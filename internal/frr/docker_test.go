@@ -13,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/metallb/frr-k8s/internal/reloader"
 	"github.com/ory/dockertest/v3"
 	"github.com/pkg/errors"
 )
@@ -29,7 +30,7 @@ const (
 func TestMain(m *testing.M) {
 	// override reloadConfig so it doesn't try to reload it.
 	debounceTimeout = time.Millisecond
-	reloadConfig = func() error { return nil }
+	reloadConfig = func() (reloader.Response, error) { return reloader.Response{Result: reloader.ResultSuccess}, nil }
 
 	flag.Parse()
 	if !testing.Short() {
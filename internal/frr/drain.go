@@ -0,0 +1,54 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import "fmt"
+
+// DrainMode is the way the routes advertised from a node are handled
+// when the node is being drained.
+type DrainMode string
+
+const (
+	// DrainDisabled leaves the advertised routes as they are.
+	DrainDisabled DrainMode = ""
+	// DrainGracefulShutdown tags the advertised routes with the GRACEFUL_SHUTDOWN community (RFC 8326).
+	DrainGracefulShutdown DrainMode = "graceful-shutdown"
+	// DrainASPathPrepend prepends the AS path of the advertised routes.
+	DrainASPathPrepend DrainMode = "as-path-prepend"
+	// DrainWithdraw withdraws the advertised routes.
+	DrainWithdraw DrainMode = "withdraw"
+)
+
+// drainASPathPrependCount is the number of times the local ASN is prepended
+// to the advertised routes when draining with DrainASPathPrepend.
+const drainASPathPrependCount = 3
+
+// ParseDrainMode validates the given drain mode.
+func ParseDrainMode(mode string) (DrainMode, error) {
+	switch m := DrainMode(mode); m {
+	case DrainDisabled, DrainGracefulShutdown, DrainASPathPrepend, DrainWithdraw:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid drain mode %s, must be one of [%s, %s, %s]", mode, DrainGracefulShutdown, DrainASPathPrepend, DrainWithdraw)
+}
+
+// DrainedConfig returns a copy of the given configuration where the routes
+// advertised are handled according to the given drain mode.
+func DrainedConfig(config *Config, mode DrainMode) *Config {
+	res := *config
+	res.Routers = make([]*RouterConfig, 0, len(config.Routers))
+	for _, r := range config.Routers {
+		router := *r
+		switch mode {
+		case DrainGracefulShutdown:
+			router.GracefulShutdown = true
+		case DrainASPathPrepend:
+			router.ASPathPrepend = drainASPathPrependCount
+		case DrainWithdraw:
+			router.IPV4Prefixes = []string{}
+			router.IPV6Prefixes = []string{}
+		}
+		res.Routers = append(res.Routers, &router)
+	}
+	return &res
+}
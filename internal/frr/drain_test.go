@@ -0,0 +1,68 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDrainedConfig(t *testing.T) {
+	config := &Config{
+		Routers: []*RouterConfig{
+			{
+				MyASN:        65000,
+				IPV4Prefixes: []string{"192.169.1.0/24"},
+				IPV6Prefixes: []string{"2001:db8::/64"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		mode     DrainMode
+		expected *RouterConfig
+	}{
+		{
+			name: "graceful shutdown",
+			mode: DrainGracefulShutdown,
+			expected: &RouterConfig{
+				MyASN:            65000,
+				IPV4Prefixes:     []string{"192.169.1.0/24"},
+				IPV6Prefixes:     []string{"2001:db8::/64"},
+				GracefulShutdown: true,
+			},
+		},
+		{
+			name: "as path prepend",
+			mode: DrainASPathPrepend,
+			expected: &RouterConfig{
+				MyASN:         65000,
+				IPV4Prefixes:  []string{"192.169.1.0/24"},
+				IPV6Prefixes:  []string{"2001:db8::/64"},
+				ASPathPrepend: drainASPathPrependCount,
+			},
+		},
+		{
+			name: "withdraw",
+			mode: DrainWithdraw,
+			expected: &RouterConfig{
+				MyASN:        65000,
+				IPV4Prefixes: []string{},
+				IPV6Prefixes: []string{},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := DrainedConfig(config, test.mode)
+			if !cmp.Equal(test.expected, res.Routers[0]) {
+				t.Fatalf("unexpected drained router (-want +got):\n%s", cmp.Diff(test.expected, res.Routers[0]))
+			}
+			if config.Routers[0].GracefulShutdown || len(config.Routers[0].IPV4Prefixes) != 1 {
+				t.Fatalf("the original config was modified")
+			}
+		})
+	}
+}
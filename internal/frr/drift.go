@@ -0,0 +1,246 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// maxDriftLines is the maximum number of differing lines reported.
+const maxDriftLines = 50
+
+// driftIgnoredPrefixes are the lines not taken into account when comparing the
+// configurations, because they are added by FRR to the running configuration,
+// because they only delimit the sections, or because they are comments.
+var driftIgnoredPrefixes = []string{
+	"!",
+	"#",
+	"Building configuration",
+	"Current configuration",
+	"frr version",
+	"frr defaults",
+	"hostname",
+	"log ",
+	"service ",
+	"line vty",
+	"ip forwarding",
+	"ipv6 forwarding",
+	"no ip forwarding",
+	"no ipv6 forwarding",
+	"end",
+	"exit",
+}
+
+// desiredConfigDrift compares the last rendered configuration with the given running one.
+// It returns false if there is no rendered configuration to compare with.
+func desiredConfigDrift(running string) ([]string, bool) {
+	desired, err := os.ReadFile(configFileName)
+	if err != nil {
+		return nil, false
+	}
+	return configDrift(string(desired), running), true
+}
+
+// configDrift compares the desired configuration with the running one, regardless of
+// the order of the lines. It returns the lines of the desired configuration missing from
+// the running one, prefixed by "-", followed by the lines of the running configuration
+// not part of the desired one, prefixed by "+". Nested lines are qualified by their
+// sections, as in "router bgp 65000 > address-family ipv4 unicast > network 192.0.2.0/24".
+func configDrift(desired, running string) []string {
+	desiredLines := normalizedConfigLines(desired)
+	runningLines := normalizedConfigLines(running)
+
+	res := []string{}
+	for _, l := range sets.List(desiredLines.Difference(runningLines)) {
+		res = append(res, "-"+l)
+	}
+	for _, l := range sets.List(runningLines.Difference(desiredLines)) {
+		if impliedByDesired(l, desiredLines) {
+			continue
+		}
+		res = append(res, "+"+l)
+	}
+	if len(res) > maxDriftLines {
+		res = append(res[:maxDriftLines], fmt.Sprintf("... and %d more lines", len(res)-maxDriftLines))
+	}
+	return res
+}
+
+// normalizedConfigLines returns the relevant lines of the given configuration, each
+// qualified by the sections it is nested in, as given by the indentation.
+func normalizedConfigLines(config string) sets.Set[string] {
+	type section struct {
+		indent int
+		line   string
+	}
+	res := sets.New[string]()
+	stack := []section{}
+	for _, l := range strings.Split(config, "\n") {
+		line := canonicalConfigLine(strings.Fields(l))
+		if line == "" || driftIgnored(line) {
+			continue
+		}
+		indent := len(l) - len(strings.TrimLeft(l, " \t"))
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		qualified := make([]string, 0, len(stack)+1)
+		for _, s := range stack {
+			qualified = append(qualified, s.line)
+		}
+		qualified = append(qualified, line)
+		res.Insert(strings.Join(qualified, " > "))
+		stack = append(stack, section{indent: indent, line: line})
+	}
+	return res
+}
+
+// driftIgnoredLines are the lines FRR omits from the running configuration, because
+// they set the default values.
+var driftIgnoredLines = sets.New(
+	"detect-multiplier 3",
+	"receive-interval 300",
+	"transmit-interval 300",
+	"echo-interval 50",
+	"minimum-ttl 254",
+)
+
+func driftIgnored(line string) bool {
+	if driftIgnoredLines.Has(line) {
+		return true
+	}
+	for _, p := range driftIgnoredPrefixes {
+		if strings.HasPrefix(line, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalConfigLine joins the fields of a configuration line, rewriting the
+// statements FRR prints differently from how they are rendered.
+func canonicalConfigLine(fields []string) string {
+	switch {
+	case len(fields) > 3 && (fields[0] == "ip" || fields[0] == "ipv6") && fields[1] == "prefix-list":
+		// FRR assigns a sequence number to the entries without one, and
+		// prints ge before le.
+		res := []string{}
+		var ge, le []string
+		for i := 0; i < len(fields); i++ {
+			switch {
+			case fields[i] == "seq" && i+1 < len(fields):
+				i++
+			case fields[i] == "ge" && i+1 < len(fields):
+				ge = fields[i : i+2]
+				i++
+			case fields[i] == "le" && i+1 < len(fields):
+				le = fields[i : i+2]
+				i++
+			default:
+				res = append(res, fields[i])
+			}
+		}
+		fields = append(append(res, ge...), le...)
+	case len(fields) == 3 && fields[0] == "neighbor" && fields[2] == "ebgp-multihop":
+		// FRR prints the maximum ttl when none is given.
+		fields = append(fields, "255")
+	case len(fields) == 3 && fields[0] == "echo" && (fields[1] == "transmit-interval" || fields[1] == "receive-interval"):
+		// echo-interval sets both the echo intervals, and FRR prints them separately.
+		fields = []string{"echo-interval", fields[2]}
+	}
+	return strings.Join(fields, " ")
+}
+
+// impliedByDesired tells if the given running line is added by FRR as a consequence
+// of a desired one, as "neighbor X bfd" for "neighbor X bfd profile P".
+func impliedByDesired(line string, desired sets.Set[string]) bool {
+	if !strings.HasSuffix(line, " bfd") {
+		return false
+	}
+	for d := range desired {
+		if strings.HasPrefix(d, line+" profile ") {
+			return true
+		}
+	}
+	return false
+}
+
+// DriftMode tells what to do when the running configuration drifts from the rendered one.
+type DriftMode string
+
+const (
+	// DriftReport only reports the drift in the status.
+	DriftReport DriftMode = "report"
+	// DriftEnforce re-applies the rendered configuration.
+	DriftEnforce DriftMode = "enforce"
+)
+
+// defaultDriftCheckInterval is the default interval the running configuration
+// is compared with the rendered one at.
+const defaultDriftCheckInterval = time.Minute
+
+// ParseDriftMode validates the given drift mode.
+func ParseDriftMode(mode string) (DriftMode, error) {
+	switch m := DriftMode(mode); m {
+	case DriftReport, DriftEnforce:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid drift mode %s, must be one of [%s, %s]", mode, DriftReport, DriftEnforce)
+}
+
+// maxUnhealedDrifts is the number of consecutive heals leaving the same drift after
+// which the drift is only reported, as re-applying the configuration does not fix it.
+const maxUnhealedDrifts = 3
+
+// healDrift re-applies the rendered configuration if the running one drifted from it
+// and the drift mode is DriftEnforce. Failed reloads are not healed, as they are already
+// retried and eventually rolled back. Drifts that persist after maxUnhealedDrifts heals
+// are not healed anymore, until a new configuration is applied.
+func (f *FRR) healDrift(ctx context.Context, l log.Logger) {
+	if f.driftMode != DriftEnforce {
+		return
+	}
+	f.Lock()
+	drift := f.Status.ConfigDrift
+	drifted := len(drift) > 0
+	failed := f.Status.LastReloadResult != ReloadSuccess || f.rollbackCause != ""
+	if !drifted || failed {
+		f.healRequested = false
+		f.Unlock()
+		return
+	}
+	if !reflect.DeepEqual(drift, f.healedDrift) {
+		f.healedDrift = drift
+		f.unhealedDrifts = 0
+	}
+	if f.unhealedDrifts >= maxUnhealedDrifts {
+		giveUp := f.unhealedDrifts == maxUnhealedDrifts
+		f.unhealedDrifts = maxUnhealedDrifts + 1
+		f.healRequested = false
+		f.Unlock()
+		if giveUp {
+			level.Warn(l).Log("op", "drift", "msg", "the drift persists after re-applying the configuration, only reporting it", "heals", maxUnhealedDrifts)
+		}
+		return
+	}
+	f.unhealedDrifts++
+	f.healRequested = true
+	f.Unlock()
+
+	level.Info(l).Log("op", "drift", "msg", "re-applying the rendered configuration")
+	driftHeals.Inc()
+	select {
+	case f.reloadConfig <- reloadEvent{useOld: true}:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,378 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/google/go-cmp/cmp"
+	"github.com/metallb/frr-k8s/internal/ipfamily"
+	"github.com/metallb/frr-k8s/internal/reloader"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestConfigDrift(t *testing.T) {
+	desired := `log file /etc/frr/frr.log informational
+hostname dummyhostname
+
+ip prefix-list 192.168.1.2-pl-ipv4 seq 1 permit 192.169.1.0/24
+route-map 192.168.1.2-out permit 1
+  match ip address prefix-list 192.168.1.2-pl-ipv4
+
+router bgp 65000
+  no bgp default ipv4-unicast
+
+  neighbor 192.168.1.2 remote-as 65001
+
+  address-family ipv4 unicast
+    neighbor 192.168.1.2 activate
+  exit-address-family
+  address-family ipv4 unicast
+    network 192.169.1.0/24
+  exit-address-family
+`
+
+	tests := []struct {
+		name     string
+		running  string
+		expected []string
+	}{
+		{
+			name: "same config, different format",
+			running: `Building configuration...
+
+Current configuration:
+!
+frr version 9.0.2_git
+frr defaults traditional
+hostname frr-k8s
+log file /etc/frr/frr.log
+!
+router bgp 65000
+ no bgp default ipv4-unicast
+ neighbor 192.168.1.2 remote-as 65001
+ !
+ address-family ipv4 unicast
+  network 192.169.1.0/24
+  neighbor 192.168.1.2 activate
+ exit-address-family
+exit
+!
+ip prefix-list 192.168.1.2-pl-ipv4 seq 1 permit 192.169.1.0/24
+!
+route-map 192.168.1.2-out permit 1
+ match ip address prefix-list 192.168.1.2-pl-ipv4
+exit
+!
+end
+`,
+			expected: []string{},
+		},
+		{
+			name: "changed out of band",
+			running: `router bgp 65000
+ no bgp default ipv4-unicast
+ neighbor 192.168.1.2 remote-as 65001
+ neighbor 192.168.1.2 shutdown
+ address-family ipv4 unicast
+  neighbor 192.168.1.2 activate
+ exit-address-family
+exit
+ip prefix-list 192.168.1.2-pl-ipv4 seq 1 permit 192.169.1.0/24
+route-map 192.168.1.2-out permit 1
+ match ip address prefix-list 192.168.1.2-pl-ipv4
+exit
+`,
+			expected: []string{
+				"-router bgp 65000 > address-family ipv4 unicast > network 192.169.1.0/24",
+				"+router bgp 65000 > neighbor 192.168.1.2 shutdown",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.expected, configDrift(desired, test.running)); diff != "" {
+				t.Fatalf("drift different from expected: %s", diff)
+			}
+		})
+	}
+}
+
+// TestConfigDriftRunningConfig compares the golden configurations with the running
+// configurations FRR shows once they are applied, which must not drift.
+func TestConfigDriftRunningConfig(t *testing.T) {
+	tests := []string{
+		"TestSingleSession",
+		"TestSingleSessionWithEBGPMultihopAndExtras",
+		"TestTwoRoutersTwoNeighbors",
+		"TestTwoRoutersTwoNeighborsBFD",
+		"TestTwoSessionsAcceptV4AndV6",
+	}
+	for _, name := range tests {
+		t.Run(name, func(t *testing.T) {
+			desired, err := os.ReadFile(filepath.Join(testData, name+".golden"))
+			if err != nil {
+				t.Fatalf("failed to read the golden file: %v", err)
+			}
+			running, err := os.ReadFile(filepath.Join(testData, "running-config", name))
+			if err != nil {
+				t.Fatalf("failed to read the running config: %v", err)
+			}
+			if drift := configDrift(string(desired), string(running)); len(drift) != 0 {
+				t.Fatalf("expecting no drift, got %v", drift)
+			}
+
+			// A line removed from the running configuration is still reported.
+			missing := strings.Replace(string(running), "\n  neighbor 192.168.1.2 activate\n", "\n", 1)
+			if drift := configDrift(string(desired), missing); len(drift) != 1 {
+				t.Fatalf("expecting one missing line, got %v", drift)
+			}
+		})
+	}
+}
+
+func TestConfigDriftScopedRawConfig(t *testing.T) {
+	config := &Config{
+		Routers: []*RouterConfig{
+			{
+				MyASN: 65000,
+				Neighbors: []*NeighborConfig{
+					{
+						IPFamily: ipfamily.IPv4,
+						ASN:      65001,
+						Addr:     "192.168.1.2",
+						RawConfig: ScopedRawConfig{
+							Config: "neighbor 192.168.1.2 description foo",
+							IPV4:   "neighbor 192.168.1.2 allowas-in 1",
+						},
+					},
+				},
+				RawConfig: ScopedRawConfig{
+					Config: "bgp bestpath as-path multipath-relax",
+					IPV4:   "maximum-paths 8",
+				},
+			},
+		},
+	}
+	rendered, err := templateConfig(config)
+	if err != nil {
+		t.Fatalf("failed to render the config: %s", err)
+	}
+
+	// The snippets are rendered without indentation, and they must still be qualified
+	// by their sections, the same way FRR reports them in the running config.
+	lines := normalizedConfigLines(rendered)
+	for _, l := range []string{
+		"router bgp 65000 > bgp bestpath as-path multipath-relax",
+		"router bgp 65000 > neighbor 192.168.1.2 description foo",
+		"router bgp 65000 > address-family ipv4 unicast > maximum-paths 8",
+		"router bgp 65000 > address-family ipv4 unicast > neighbor 192.168.1.2 allowas-in 1",
+	} {
+		if !lines.Has(l) {
+			t.Fatalf("expected %q in the normalized config lines, got %v", l, sets.List(lines))
+		}
+	}
+
+	running := `router bgp 65000
+ no bgp ebgp-requires-policy
+ bgp bestpath as-path multipath-relax
+ neighbor 192.168.1.2 description foo
+ address-family ipv4 unicast
+  maximum-paths 8
+  neighbor 192.168.1.2 allowas-in 1
+ exit-address-family
+exit
+`
+	for _, d := range configDrift(rendered, running) {
+		for _, l := range []string{"bestpath", "description", "maximum-paths", "allowas-in"} {
+			if strings.Contains(d, l) {
+				t.Fatalf("unexpected drift %q for a scoped raw config line", d)
+			}
+		}
+	}
+}
+
+func TestHealDrift(t *testing.T) {
+	t.Setenv("FRR_CONFIG_FILE", filepath.Join(t.TempDir(), "frr.conf"))
+	oldReload := reloadConfig
+	defer func() { reloadConfig = oldReload }()
+	reloadConfig = func() (reloader.Response, error) {
+		return reloader.Response{Result: reloader.ResultSuccess}, nil
+	}
+
+	tests := []struct {
+		name       string
+		mode       DriftMode
+		status     Status
+		expectHeal bool
+	}{
+		{
+			name:   "report only",
+			mode:   DriftReport,
+			status: Status{LastReloadResult: ReloadSuccess, ConfigDrift: []string{"+foo"}},
+		},
+		{
+			name:   "no drift",
+			mode:   DriftEnforce,
+			status: Status{LastReloadResult: ReloadSuccess},
+		},
+		{
+			name:   "failed reload",
+			mode:   DriftEnforce,
+			status: Status{LastReloadResult: "failed", ConfigDrift: []string{"+foo"}},
+		},
+		{
+			name:       "enforce",
+			mode:       DriftEnforce,
+			status:     Status{LastReloadResult: ReloadSuccess, ConfigDrift: []string{"+foo"}},
+			expectHeal: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			frr := &FRR{
+				reloadConfig: make(chan reloadEvent, 1),
+				driftMode:    test.mode,
+				Status:       test.status,
+			}
+			config := &Config{Hostname: "host"}
+			if err := frr.reload(config, log.NewNopLogger()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			frr.healDrift(context.Background(), log.NewNopLogger())
+			select {
+			case event := <-frr.reloadConfig:
+				if !test.expectHeal {
+					t.Fatalf("not expecting the config to be re-applied")
+				}
+				if !event.useOld {
+					t.Fatalf("expecting the current config to be re-applied")
+				}
+			case <-time.After(10 * time.Millisecond):
+				if test.expectHeal {
+					t.Fatalf("expecting the config to be re-applied")
+				}
+				return
+			}
+
+			if err := frr.reload(config, log.NewNopLogger()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			history := frr.GetStatus().History
+			if trigger := history[len(history)-1].Trigger; trigger != TriggerDriftHeal {
+				t.Fatalf("expecting trigger %s, got %s", TriggerDriftHeal, trigger)
+			}
+		})
+	}
+}
+
+func TestHealDriftGivesUp(t *testing.T) {
+	t.Setenv("FRR_CONFIG_FILE", filepath.Join(t.TempDir(), "frr.conf"))
+	oldReload := reloadConfig
+	defer func() { reloadConfig = oldReload }()
+	reloadConfig = func() (reloader.Response, error) {
+		return reloader.Response{Result: reloader.ResultSuccess}, nil
+	}
+
+	frr := &FRR{
+		reloadConfig: make(chan reloadEvent, 1),
+		driftMode:    DriftEnforce,
+	}
+	setDrift := func(drift ...string) {
+		frr.Lock()
+		frr.Status = Status{LastReloadResult: ReloadSuccess, ConfigDrift: drift}
+		frr.Unlock()
+	}
+	healed := func() bool {
+		frr.healDrift(context.Background(), log.NewNopLogger())
+		select {
+		case <-frr.reloadConfig:
+			return true
+		default:
+			return false
+		}
+	}
+
+	config := &Config{Hostname: "host"}
+	if err := frr.reload(config, log.NewNopLogger()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	setDrift("+foo")
+	for i := 0; i < maxUnhealedDrifts; i++ {
+		if !healed() {
+			t.Fatalf("expecting the config to be re-applied, attempt %d", i)
+		}
+		if err := frr.reload(config, log.NewNopLogger()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// The heal leaves the same drift.
+		setDrift("+foo")
+	}
+	if healed() {
+		t.Fatalf("not expecting the config to be re-applied after %d heals leaving the same drift", maxUnhealedDrifts)
+	}
+
+	setDrift("+foo", "+bar")
+	if !healed() {
+		t.Fatalf("expecting a different drift to be healed")
+	}
+	for i := 1; i < maxUnhealedDrifts; i++ {
+		if !healed() {
+			t.Fatalf("expecting the config to be re-applied, attempt %d", i)
+		}
+	}
+	if healed() {
+		t.Fatalf("not expecting the config to be re-applied after %d heals leaving the same drift", maxUnhealedDrifts)
+	}
+
+	if err := frr.reload(&Config{Hostname: "other"}, log.NewNopLogger()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	setDrift("+foo", "+bar")
+	if !healed() {
+		t.Fatalf("expecting the drift to be healed after a new config is applied")
+	}
+}
+
+func TestHealDriftStopped(t *testing.T) {
+	frr := &FRR{
+		reloadConfig: make(chan reloadEvent),
+		driftMode:    DriftEnforce,
+		Status: Status{
+			LastReloadResult: ReloadSuccess,
+			ConfigDrift:      []string{"+router bgp 65000 > neighbor 192.168.1.2 shutdown"},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Nobody is reading the reload requests once the context is done.
+	done := make(chan struct{})
+	go func() {
+		frr.healDrift(ctx, log.NewNopLogger())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("healDrift blocked after the context was done")
+	}
+}
+
+func TestParseDriftMode(t *testing.T) {
+	if _, err := ParseDriftMode("enforce"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ParseDriftMode("foo"); err == nil {
+		t.Fatalf("expecting error, got nil")
+	}
+}
@@ -6,10 +6,13 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/metallb/frr-k8s/internal/logging"
@@ -29,6 +32,16 @@ type Status struct {
 	updateTime       string
 	Current          string
 	LastReloadResult string
+	// RolledBack tells if FRR was reverted to the last configuration
+	// reloaded successfully, because the latest one failed to reload.
+	RolledBack bool
+	// History contains the latest reload attempts, the oldest first.
+	History []ReloadAttempt
+	// ConfigDrift contains the differences between the rendered configuration
+	// and the running one, as returned by configDrift. ConfigDriftKnown tells if
+	// they were compared.
+	ConfigDrift      []string
+	ConfigDriftKnown bool
 }
 
 type FRR struct {
@@ -36,11 +49,49 @@ type FRR struct {
 	logLevel        string
 	Status          Status
 	onStatusChanged StatusChanged
+	// statusUpdated is notified when a new status may be available.
+	statusUpdated chan struct{}
+	// lastGoodConfig is the last configuration reloaded successfully, and failedReloads
+	// the number of consecutive failed attempts to reload the current one. They are
+	// accessed only by the debouncer.
+	lastGoodConfig *Config
+	failedReloads  int
+	// lastAttemptedConfig is the last configuration the debouncer tried to reload,
+	// used to tell a retry from a configuration change.
+	lastAttemptedConfig *Config
+	// requestedConfig is the latest configuration received and requestedAt the time
+	// it was received at, until it gets applied.
+	requestedConfig *Config
+	requestedAt     time.Time
+	history         []ReloadAttempt
+	driftMode       DriftMode
+	// healRequested tells the next reload is meant to fix a drift of the running configuration.
+	healRequested bool
+	// healedDrift is the drift the last heal was requested for, and unhealedDrifts the
+	// number of consecutive heals that left it unchanged. They are reset when a new
+	// configuration is applied.
+	healedDrift    []string
+	unhealedDrifts int
+	// persistedConfigFile is where the last configuration reloaded successfully is
+	// stored, and lastPersistedConfig the last configuration stored.
+	persistedConfigFile string
+	lastPersistedConfig *Config
+	// reloading is held while a configuration is being reloaded.
+	reloading sync.Mutex
+	// shutdownDone is closed once the routes are drained on shutdown.
+	shutdownDone chan struct{}
+	// rollbackCause is the error of the configuration that caused the rollback, if
+	// FRR is running with the last good configuration.
+	rollbackCause string
 	sync.Mutex
 }
 
 const ReloadSuccess = "success"
 
+// rollbackAfterFailures is the number of consecutive failed reloads of a
+// configuration after which FRR is reverted to the last good configuration.
+var rollbackAfterFailures = 3
+
 // Create a variable for os.Hostname() in order to make it easy to mock out
 // in unit tests.
 var osHostname = os.Hostname
@@ -52,63 +103,245 @@ func (f *FRR) ApplyConfig(config *Config) error {
 	}
 
 	// TODO add internal wrapper
-	config.Loglevel = f.logLevel
+	if config.Loglevel == "" {
+		config.Loglevel = f.logLevel
+	}
 	config.Hostname = hostname
+
+	f.Lock()
+	if !reflect.DeepEqual(config, f.requestedConfig) {
+		f.requestedConfig = config
+		f.requestedAt = time.Now()
+	}
+	f.Unlock()
+
 	f.reloadConfig <- reloadEvent{config: config}
 	return nil
 }
 
 var debounceTimeout = 3 * time.Second
 var failureTimeout = time.Second * 5
+var maxFailureTimeout = 5 * time.Minute
+
+// retryJitter is the maximum fraction of the retry interval randomly added to it.
+const retryJitter = 0.1
+
+// Options are the optional parameters of the FRR instance.
+type Options struct {
+	// DriftMode tells what to do when the running configuration drifts
+	// from the rendered one. Defaults to DriftReport.
+	DriftMode DriftMode
+	// DriftCheckInterval is the interval the running configuration is compared
+	// with the rendered one at. Defaults to one minute.
+	DriftCheckInterval time.Duration
+	// DebounceInterval is the time the configuration changes are coalesced for
+	// before being applied. Defaults to three seconds.
+	DebounceInterval time.Duration
+	// RetryInterval is the interval a failed reload is first retried after. It is
+	// doubled at each consecutive failure, up to MaxRetryInterval. Defaults to five
+	// seconds and five minutes.
+	RetryInterval    time.Duration
+	MaxRetryInterval time.Duration
+	// PersistedConfigFile is the file the last configuration reloaded successfully
+	// is stored in, and applied from at startup. Leave empty to disable it.
+	PersistedConfigFile string
+	// ShutdownMode tells how the advertised routes are handled when the given
+	// context is done, before the daemon exits. Defaults to ShutdownDisabled.
+	ShutdownMode ShutdownMode
+	// ShutdownDrainPeriod is the time the peers are given to converge after the
+	// routes are drained on shutdown. Zero means not waiting, and a negative value
+	// means the default of 30 seconds.
+	ShutdownDrainPeriod time.Duration
+}
 
-func NewFRR(ctx context.Context, onStatusChanged StatusChanged, logger log.Logger, logLevel logging.Level) *FRR {
+func NewFRR(ctx context.Context, onStatusChanged StatusChanged, logger log.Logger, logLevel logging.Level, options Options) *FRR {
+	if options.DriftMode == "" {
+		options.DriftMode = DriftReport
+	}
+	if options.DriftCheckInterval == 0 {
+		options.DriftCheckInterval = defaultDriftCheckInterval
+	}
+	if options.DebounceInterval == 0 {
+		options.DebounceInterval = debounceTimeout
+	}
+	if options.RetryInterval == 0 {
+		options.RetryInterval = failureTimeout
+	}
+	if options.MaxRetryInterval == 0 {
+		options.MaxRetryInterval = maxFailureTimeout
+	}
+	if options.MaxRetryInterval < options.RetryInterval {
+		options.MaxRetryInterval = options.RetryInterval
+	}
+	if options.ShutdownDrainPeriod < 0 {
+		options.ShutdownDrainPeriod = defaultShutdownDrainPeriod
+	}
 	res := &FRR{
-		reloadConfig:    make(chan reloadEvent),
-		logLevel:        logLevelToFRR(logLevel),
-		onStatusChanged: onStatusChanged,
+		reloadConfig:        make(chan reloadEvent),
+		logLevel:            LogLevelToFRR(logLevel),
+		driftMode:           options.DriftMode,
+		onStatusChanged:     onStatusChanged,
+		statusUpdated:       make(chan struct{}, 1),
+		persistedConfigFile: options.PersistedConfigFile,
+		shutdownDone:        make(chan struct{}),
 	}
 	reload := func(config *Config) error {
-		return generateAndReloadConfigFile(config, logger)
+		res.reloading.Lock()
+		defer res.reloading.Unlock()
+		// Once shutting down, the configuration is left to drainOnShutdown.
+		if ctx.Err() != nil {
+			return nil
+		}
+		err := res.reload(config, logger)
+		// The reloader replies once the reload is completed, so the
+		// new status is already available.
+		res.notifyStatusUpdated()
+		return err
 	}
 
-	debouncer(ctx, reload, res.reloadConfig, debounceTimeout, failureTimeout, logger)
-	res.pollStatus(ctx, logger)
+	backoff := retryBackoff{
+		initial: options.RetryInterval,
+		max:     options.MaxRetryInterval,
+		jitter:  retryJitter,
+	}
+	debouncer(ctx, reload, res.reloadConfig, options.DebounceInterval, backoff, logger)
+	res.watchStatus(ctx, options.DriftCheckInterval, logger)
+	go res.drainOnShutdown(ctx, options.ShutdownMode, options.ShutdownDrainPeriod, logger)
+	res.applyPersistedConfig(logger)
 	return res
 }
 
 func (f *FRR) GetStatus() Status {
 	f.Lock()
 	defer f.Unlock()
-	return f.Status
+	res := f.Status
+	res.History = append([]ReloadAttempt{}, f.history...)
+	if f.rollbackCause != "" {
+		res.RolledBack = true
+		res.LastReloadResult = fmt.Sprintf("rolled back to the last good configuration: %s", f.rollbackCause)
+	}
+	return res
 }
 
-func (f *FRR) pollStatus(ctx context.Context, l log.Logger) {
-	var tickerIntervals = 30 * time.Second
-	ticker := time.NewTicker(tickerIntervals)
+// reload applies the given configuration. After rollbackAfterFailures consecutive failures,
+// FRR is reverted to the last configuration reloaded successfully, and the given one is
+// not retried until a new configuration is applied.
+func (f *FRR) reload(config *Config, l log.Logger) error {
+	f.Lock()
+	healing := f.healRequested
+	f.healRequested = false
+	if config != f.lastAttemptedConfig {
+		f.healedDrift = nil
+		f.unhealedDrifts = 0
+	}
+	f.Unlock()
+
+	trigger := TriggerConfigChange
+	switch {
+	case config != f.lastAttemptedConfig:
+	case healing:
+		trigger = TriggerDriftHeal
+	default:
+		trigger = TriggerRetry
+	}
+	f.lastAttemptedConfig = config
+
+	err := f.attemptReload(config, trigger, l)
+	if err == nil {
+		f.lastGoodConfig = config
+		f.failedReloads = 0
+		f.persistConfig(config, l)
+		f.setRollbackCause("")
+		return nil
+	}
+
+	f.failedReloads++
+	if f.failedReloads < rollbackAfterFailures || f.lastGoodConfig == nil || f.lastGoodConfig == config {
+		return err
+	}
+
+	level.Warn(l).Log("op", "reload", "msg", "rolling back to the last good configuration", "failures", f.failedReloads, "error", err)
+	if rollbackErr := f.attemptReload(f.lastGoodConfig, TriggerRollback, l); rollbackErr != nil {
+		level.Error(l).Log("op", "reload", "msg", "failed to roll back to the last good configuration", "error", rollbackErr)
+		return err
+	}
+	f.failedReloads = 0
+	rollbacks.Inc()
+	f.setRollbackCause(err.Error())
+	return nil
+}
+
+// setRollbackCause stores the cause of the rollback, notifying the change of status.
+func (f *FRR) setRollbackCause(cause string) {
+	f.Lock()
+	changed := f.rollbackCause != cause
+	f.rollbackCause = cause
+	f.Unlock()
+	if !changed {
+		return
+	}
+	rolledBack.Set(0)
+	if cause != "" {
+		rolledBack.Set(1)
+	}
+	if f.onStatusChanged != nil {
+		f.onStatusChanged()
+	}
+}
+
+// statusPollInterval is the interval the status is fetched at, regardless
+// of the notifications of its changes.
+var statusPollInterval = 30 * time.Second
+
+// notifyStatusUpdated triggers a fetch of the status, without blocking
+// if one is already pending.
+func (f *FRR) notifyStatusUpdated() {
+	select {
+	case f.statusUpdated <- struct{}{}:
+	default:
+	}
+}
+
+// watchStatus fetches the status every time the reloader reports a new one, either
+// by replying to a reload request or by updating the status file. The status
+// is also polled periodically, in case a notification was missed. Every driftCheckInterval,
+// the running configuration is checked against the rendered one.
+func (f *FRR) watchStatus(ctx context.Context, driftCheckInterval time.Duration, l log.Logger) {
+	ticker := time.NewTicker(statusPollInterval)
+	driftTicker := time.NewTicker(driftCheckInterval)
+	var fileEvents <-chan fsnotify.Event
+	watcher, err := watchStatusFile()
+	if err != nil {
+		level.Error(l).Log("op", "watch status", "error", err, "msg", "falling back to polling")
+	} else {
+		fileEvents = watcher.Events
+	}
+
 	go func() {
+		defer ticker.Stop()
+		defer driftTicker.Stop()
+		if watcher != nil {
+			defer watcher.Close()
+		}
 		for {
 			select {
 			case <-ticker.C:
-				status, err := fetchStatus()
-				if err != nil {
-					// This doesn't mean the reload failed, but
-					// that we were not able to fetch the status
-					level.Error(l).Log("op", "fetch status", "error", err)
-					break
-				}
-				if status.updateTime == f.Status.updateTime {
-					break
-				}
-				if status.LastReloadResult != ReloadSuccess {
-					level.Error(l).Log("op", "fetch status", "lastReloadResult", "failed")
-					f.reloadConfig <- reloadEvent{useOld: true}
+				f.updateStatus(l)
+			case <-driftTicker.C:
+				f.updateStatus(l)
+				f.healDrift(ctx, l)
+			case <-f.statusUpdated:
+				f.updateStatus(l)
+			case event, ok := <-fileEvents:
+				if !ok {
+					fileEvents = nil
+					continue
 				}
-				f.Lock()
-				f.Status = status
-				f.Unlock()
-				if f.onStatusChanged != nil {
-					f.onStatusChanged()
+				if filepath.Clean(event.Name) != filepath.Clean(statusFileName) ||
+					!event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+					continue
 				}
+				f.updateStatus(l)
 			case <-ctx.Done():
 				return
 			}
@@ -116,7 +349,64 @@ func (f *FRR) pollStatus(ctx context.Context, l log.Logger) {
 	}()
 }
 
-const (
+// watchStatusFile returns a watcher on the directory containing the status file,
+// as the file may be created or replaced by the reloader.
+func watchStatusFile() (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the status watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(statusFileName)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch the status directory: %w", err)
+	}
+	return watcher, nil
+}
+
+// updateStatus fetches the status and, if changed, stores it and notifies it.
+func (f *FRR) updateStatus(l log.Logger) {
+	status, err := fetchStatus()
+	if err != nil {
+		// This doesn't mean the reload failed, but
+		// that we were not able to fetch the status
+		level.Error(l).Log("op", "fetch status", "error", err)
+		return
+	}
+	// The running config stored by the reloader is refreshed only after a reload,
+	// while the live one includes the changes applied out of band too.
+	if running, err := fetchRunningConfig(); err == nil {
+		status.Current = running
+	} else {
+		level.Debug(l).Log("op", "fetch status", "msg", "failed to fetch the live running config", "error", err)
+	}
+	status.ConfigDrift, status.ConfigDriftKnown = desiredConfigDrift(status.Current)
+
+	f.Lock()
+	unchanged := reflect.DeepEqual(status, f.Status)
+	newReload := status.updateTime != f.Status.updateTime
+	f.Unlock()
+	if unchanged {
+		return
+	}
+	// Failed reloads are retried by the debouncer, as the reloader reports
+	// the result when replying.
+	if newReload && status.LastReloadResult != ReloadSuccess {
+		level.Error(l).Log("op", "fetch status", "lastReloadResult", "failed")
+	}
+	configDrifted.Set(0)
+	if len(status.ConfigDrift) > 0 {
+		level.Info(l).Log("op", "fetch status", "msg", "the running config differs from the rendered one", "drift", strings.Join(status.ConfigDrift, "\n"))
+		configDrifted.Set(1)
+	}
+	f.Lock()
+	f.Status = status
+	f.Unlock()
+	if f.onStatusChanged != nil {
+		f.onStatusChanged()
+	}
+}
+
+var (
 	statusFileName    = "/etc/frr_reloader/.status"
 	runningConfig     = "/etc/frr_reloader/running-config"
 	lastAppliedResult = "/etc/frr_reloader/last-error"
@@ -163,7 +453,8 @@ func readLastReloadResult() (string, string, error) {
 	return lastReloadStatus[0], lastReloadStatus[1], nil
 }
 
-func logLevelToFRR(level logging.Level) string {
+// LogLevelToFRR converts the given log level to the corresponding FRR one.
+func LogLevelToFRR(level logging.Level) string {
 	// Allowed frr log levels are: emergencies, alerts, critical,
 	// 		errors, warnings, notifications, informational, or debugging
 	switch level {
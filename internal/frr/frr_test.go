@@ -100,7 +100,7 @@ var emptyCB = func() {}
 func TestSingleSession(t *testing.T) {
 	testSetup(t)
 	ctx, cancel := context.WithCancel(context.Background())
-	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo)
+	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo, Options{})
 	defer cancel()
 
 	config := Config{
@@ -139,10 +139,104 @@ func TestSingleSession(t *testing.T) {
 	testCheckConfigFile(t)
 }
 
+// testSingleSessionDraining applies a single session config, changed as
+// the given drain mode does.
+func testSingleSessionDraining(t *testing.T, drain func(r *RouterConfig)) {
+	testSetup(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo, Options{})
+	defer cancel()
+
+	config := Config{
+		Routers: []*RouterConfig{
+			{
+				MyASN: 65000,
+				Neighbors: []*NeighborConfig{
+					{
+						IPFamily: ipfamily.IPv4,
+						ASN:      65001,
+						Addr:     "192.168.1.2",
+						Outgoing: AllowedOut{
+							PrefixesV4: []OutgoingFilter{
+								{
+									IPFamily: ipfamily.IPv4,
+									Prefix:   "192.169.1.0/24",
+								},
+							},
+						},
+					},
+				},
+				IPV4Prefixes: []string{"192.169.1.0/24"},
+			},
+		},
+	}
+	drain(config.Routers[0])
+	err := frr.ApplyConfig(&config)
+	if err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestSingleSessionDrainingGracefulShutdown(t *testing.T) {
+	testSingleSessionDraining(t, func(r *RouterConfig) {
+		r.GracefulShutdown = true
+	})
+}
+
+func TestSingleSessionDrainingASPathPrepend(t *testing.T) {
+	testSingleSessionDraining(t, func(r *RouterConfig) {
+		r.ASPathPrepend = 3
+	})
+}
+
+func TestSingleSessionDrainingWithdraw(t *testing.T) {
+	testSingleSessionDraining(t, func(r *RouterConfig) {
+		r.IPV4Prefixes = []string{}
+		r.IPV6Prefixes = []string{}
+	})
+}
+
+func TestTwoSessionsOneDisabled(t *testing.T) {
+	testSetup(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo, Options{})
+	defer cancel()
+
+	config := Config{
+		Routers: []*RouterConfig{
+			{
+				MyASN: 65000,
+				Neighbors: []*NeighborConfig{
+					{
+						IPFamily: ipfamily.IPv4,
+						ASN:      65001,
+						Addr:     "192.168.1.2",
+					},
+					{
+						IPFamily:        ipfamily.IPv4,
+						ASN:             65001,
+						Addr:            "192.168.1.3",
+						Disabled:        true,
+						DisabledMessage: "upstream maintenance",
+					},
+				},
+			},
+		},
+	}
+	err := frr.ApplyConfig(&config)
+	if err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
 func TestTwoRoutersTwoNeighbors(t *testing.T) {
 	testSetup(t)
 	ctx, cancel := context.WithCancel(context.Background())
-	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo)
+	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo, Options{})
 	defer cancel()
 
 	config := Config{
@@ -216,7 +310,7 @@ func TestTwoRoutersTwoNeighbors(t *testing.T) {
 func TestTwoSessionsAcceptAll(t *testing.T) {
 	testSetup(t)
 	ctx, cancel := context.WithCancel(context.Background())
-	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo)
+	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo, Options{})
 	defer cancel()
 
 	config := Config{
@@ -254,7 +348,7 @@ func TestTwoSessionsAcceptAll(t *testing.T) {
 func TestTwoSessionsAcceptSomeV4(t *testing.T) {
 	testSetup(t)
 	ctx, cancel := context.WithCancel(context.Background())
-	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo)
+	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo, Options{})
 	defer cancel()
 
 	config := Config{
@@ -306,7 +400,7 @@ func TestTwoSessionsAcceptSomeV4(t *testing.T) {
 func TestTwoSessionsAcceptV4AndV6(t *testing.T) {
 	testSetup(t)
 	ctx, cancel := context.WithCancel(context.Background())
-	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo)
+	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo, Options{})
 	defer cancel()
 
 	config := Config{
@@ -425,7 +519,7 @@ func TestSingleSessionWithEBGPMultihop(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo)
+	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo, Options{})
 
 	config := Config{
 		Routers: []*RouterConfig{
@@ -470,7 +564,7 @@ func TestSingleSessionWithIPv6SingleHop(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo)
+	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo, Options{})
 
 	config := Config{
 		Routers: []*RouterConfig{
@@ -511,7 +605,7 @@ func TestMultipleNeighborsOneV4AndOneV6(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo)
+	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo, Options{})
 
 	config := Config{
 		Routers: []*RouterConfig{
@@ -566,7 +660,7 @@ func TestMultipleRoutersMultipleNeighs(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo)
+	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo, Options{})
 
 	config := Config{
 		Routers: []*RouterConfig{
@@ -657,7 +751,7 @@ func TestSingleSessionWithEBGPMultihopAndExtras(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo)
+	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo, Options{})
 
 	config := Config{
 		Routers: []*RouterConfig{
@@ -697,13 +791,54 @@ func TestSingleSessionWithEBGPMultihopAndExtras(t *testing.T) {
 	testCheckConfigFile(t)
 }
 
+func TestSingleSessionWithScopedRawConfig(t *testing.T) {
+	testSetup(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo, Options{})
+
+	config := Config{
+		Routers: []*RouterConfig{
+			{
+				MyASN: 65000,
+				Neighbors: []*NeighborConfig{
+					{
+						IPFamily: ipfamily.IPv4,
+						ASN:      65001,
+						Addr:     "192.168.1.2",
+						RawConfig: ScopedRawConfig{
+							Config: "  neighbor 192.168.1.2 description foo",
+							IPV4:   "    neighbor 192.168.1.2 allowas-in 1",
+						},
+					},
+				},
+				IPV4Prefixes: []string{"192.169.1.0/24"},
+				RawConfig: ScopedRawConfig{
+					Config: "  bgp bestpath as-path multipath-relax",
+					IPV4:   "    maximum-paths 8",
+					IPV6:   "    maximum-paths 4",
+				},
+			},
+		},
+	}
+
+	err := frr.ApplyConfig(&config)
+	if err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
 func TestSingleSessionWithAlwaysBlock(t *testing.T) {
 	testSetup(t)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo)
+	frr := NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo, Options{})
 
 	config := Config{
 		Routers: []*RouterConfig{
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"time"
+
+	"github.com/go-kit/log"
+)
+
+// ReloadTrigger is the reason of a reload attempt.
+type ReloadTrigger string
+
+const (
+	TriggerConfigChange ReloadTrigger = "ConfigChange"
+	TriggerRetry        ReloadTrigger = "Retry"
+	TriggerRollback     ReloadTrigger = "Rollback"
+	TriggerDriftHeal    ReloadTrigger = "DriftHeal"
+)
+
+// maxReloadHistory is the number of reload attempts kept in the history.
+const maxReloadHistory = 10
+
+// ReloadAttempt describes an attempt to reload the configuration of FRR.
+type ReloadAttempt struct {
+	Time     time.Time
+	Trigger  ReloadTrigger
+	Duration time.Duration
+	// Result is either ReloadSuccess or the error of the reload.
+	Result       string
+	LinesAdded   int
+	LinesRemoved int
+}
+
+// attemptReload reloads the given configuration, recording the attempt in the history.
+func (f *FRR) attemptReload(config *Config, trigger ReloadTrigger, l log.Logger) error {
+	start := time.Now()
+	res, err := generateAndReloadConfigFile(config, l)
+	duration := time.Since(start)
+	reloadDuration.Observe(duration.Seconds())
+
+	attempt := ReloadAttempt{
+		Time:     start,
+		Trigger:  trigger,
+		Duration: duration,
+		Result:   ReloadSuccess,
+	}
+	attempt.LinesAdded, attempt.LinesRemoved = res.LinesChanged()
+	if err != nil {
+		attempt.Result = err.Error()
+	}
+
+	f.Lock()
+	f.history = append(f.history, attempt)
+	if len(f.history) > maxReloadHistory {
+		f.history = f.history[len(f.history)-maxReloadHistory:]
+	}
+	if err == nil && config == f.requestedConfig && !f.requestedAt.IsZero() {
+		configToApplied.Observe(time.Since(f.requestedAt).Seconds())
+		f.requestedAt = time.Time{}
+	}
+	f.Unlock()
+
+	if f.onStatusChanged != nil {
+		f.onStatusChanged()
+	}
+	return err
+}
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/metallb/frr-k8s/internal/reloader"
+)
+
+func TestReloadHistoryBounded(t *testing.T) {
+	t.Setenv("FRR_CONFIG_FILE", filepath.Join(t.TempDir(), "frr.conf"))
+
+	oldReload := reloadConfig
+	defer func() { reloadConfig = oldReload }()
+	reloadConfig = func() (reloader.Response, error) {
+		return reloader.Response{
+			Result: reloader.ResultSuccess,
+			Diff:   "Lines To Delete\n===============\nrouter bgp 65000\n no neighbor 192.168.1.2\n\nLines To Add\n============\nrouter bgp 65000\n",
+		}, nil
+	}
+
+	frr := &FRR{}
+	for i := 0; i < maxReloadHistory+5; i++ {
+		if err := frr.reload(&Config{Hostname: "host"}, log.NewNopLogger()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	history := frr.GetStatus().History
+	if len(history) != maxReloadHistory {
+		t.Fatalf("expecting %d entries in the history, got %d", maxReloadHistory, len(history))
+	}
+	last := history[len(history)-1]
+	if last.LinesAdded != 1 || last.LinesRemoved != 2 {
+		t.Fatalf("unexpected diff summary, added %d removed %d", last.LinesAdded, last.LinesRemoved)
+	}
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"strings"
+)
+
+// ObjectKind is the kind of an object defined by name in the FRR configuration.
+type ObjectKind string
+
+const (
+	RouteMap           ObjectKind = "route-map"
+	IPPrefixList       ObjectKind = "ip prefix-list"
+	IPV6PrefixList     ObjectKind = "ipv6 prefix-list"
+	CommunityList      ObjectKind = "bgp community-list"
+	LargeCommunityList ObjectKind = "bgp large-community-list"
+	ExtCommunityList   ObjectKind = "bgp extcommunity-list"
+	BFDProfileObject   ObjectKind = "bfd profile"
+)
+
+// NamedObject is an object defined by name in the FRR configuration.
+type NamedObject struct {
+	Kind ObjectKind
+	Name string
+}
+
+// GeneratedObjects returns the named objects defined by the configuration rendered
+// from the given one, leaving out the ones coming from the raw configuration.
+func GeneratedObjects(config *Config) (map[NamedObject]bool, error) {
+	generated := Config{
+		Routers:     make([]*RouterConfig, 0, len(config.Routers)),
+		BFDProfiles: config.BFDProfiles,
+	}
+	for _, r := range config.Routers {
+		router := *r
+		router.RawConfig = ScopedRawConfig{}
+		router.Neighbors = make([]*NeighborConfig, 0, len(r.Neighbors))
+		for _, n := range r.Neighbors {
+			neighbor := *n
+			neighbor.RawConfig = ScopedRawConfig{}
+			router.Neighbors = append(router.Neighbors, &neighbor)
+		}
+		generated.Routers = append(generated.Routers, &router)
+	}
+
+	configString, err := templateConfig(&generated)
+	if err != nil {
+		return nil, err
+	}
+	res := map[NamedObject]bool{}
+	for _, o := range DefinedObjects(configString) {
+		res[o] = true
+	}
+	return res, nil
+}
+
+// DefinedObjects parses the given configuration and returns the route-maps,
+// prefix-lists, community-lists and bfd profiles it defines.
+func DefinedObjects(config string) []NamedObject {
+	const (
+		topLevel = iota
+		inBFD
+		inBFDProfile
+		inBFDPeer
+	)
+
+	res := []NamedObject{}
+	state := topLevel
+	for _, line := range strings.Split(config, "\n") {
+		tokens := strings.Fields(line)
+		if len(tokens) == 0 || strings.HasPrefix(tokens[0], "!") || strings.HasPrefix(tokens[0], "#") {
+			continue
+		}
+		if o, ok := globalObject(tokens); ok {
+			res = append(res, o)
+			state = topLevel
+			continue
+		}
+		if len(tokens) == 1 && tokens[0] == "bfd" {
+			state = inBFD
+			continue
+		}
+		if state == topLevel {
+			continue
+		}
+
+		switch tokens[0] {
+		case "profile":
+			// Inside a peer, the profile command applies an existing profile.
+			if state == inBFDPeer || len(tokens) < 2 {
+				continue
+			}
+			res = append(res, NamedObject{Kind: BFDProfileObject, Name: tokens[1]})
+			state = inBFDProfile
+		case "peer":
+			state = inBFDPeer
+		case "exit":
+			if state == inBFD {
+				state = topLevel
+				continue
+			}
+			state = inBFD
+		case "end":
+			state = topLevel
+		}
+	}
+	return res
+}
+
+// globalObject returns the object defined by the given tokens, if they
+// form a global command defining a route-map, a prefix-list or a community-list.
+func globalObject(tokens []string) (NamedObject, bool) {
+	if len(tokens) < 2 {
+		return NamedObject{}, false
+	}
+	switch {
+	case tokens[0] == "route-map":
+		return NamedObject{Kind: RouteMap, Name: tokens[1]}, true
+	case tokens[0] == "ip" && tokens[1] == "prefix-list" && len(tokens) > 2 && tokens[2] != "sequence-number":
+		return NamedObject{Kind: IPPrefixList, Name: tokens[2]}, true
+	case tokens[0] == "ipv6" && tokens[1] == "prefix-list" && len(tokens) > 2 && tokens[2] != "sequence-number":
+		return NamedObject{Kind: IPV6PrefixList, Name: tokens[2]}, true
+	case tokens[0] == "bgp" && len(tokens) > 2:
+		var kind ObjectKind
+		switch tokens[1] {
+		case "community-list":
+			kind = CommunityList
+		case "large-community-list":
+			kind = LargeCommunityList
+		case "extcommunity-list":
+			kind = ExtCommunityList
+		default:
+			return NamedObject{}, false
+		}
+		name := tokens[2]
+		if (name == "standard" || name == "expanded") && len(tokens) > 3 {
+			name = tokens[3]
+		}
+		return NamedObject{Kind: kind, Name: name}, true
+	}
+	return NamedObject{}, false
+}
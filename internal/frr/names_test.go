@@ -0,0 +1,89 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDefinedObjects(t *testing.T) {
+	config := `
+route-map foo-in permit 10
+  match ip address prefix-list foo-pl
+ip prefix-list foo-pl seq 1 permit 192.0.2.0/24
+ip prefix-list sequence-number
+ipv6 prefix-list foo-pl seq 1 permit 2001:db8::/64
+bgp community-list standard foo-comm seq 5 permit 65000:1
+bgp large-community-list 1 permit 65000:1:1
+bgp extcommunity-list expanded foo-ext permit _65000:
+router bgp 65000
+  neighbor 192.0.2.1 route-map bar-in in
+bfd
+  profile foo-bfd
+    receive-interval 100
+  peer 192.0.2.1
+    profile bar-bfd
+  exit
+  profile foo-bfd1
+  exit
+exit
+profile bar-bfd
+`
+
+	expected := []NamedObject{
+		{Kind: RouteMap, Name: "foo-in"},
+		{Kind: IPPrefixList, Name: "foo-pl"},
+		{Kind: IPV6PrefixList, Name: "foo-pl"},
+		{Kind: CommunityList, Name: "foo-comm"},
+		{Kind: LargeCommunityList, Name: "1"},
+		{Kind: ExtCommunityList, Name: "foo-ext"},
+		{Kind: BFDProfileObject, Name: "foo-bfd"},
+		{Kind: BFDProfileObject, Name: "foo-bfd1"},
+	}
+	if diff := cmp.Diff(expected, DefinedObjects(config)); diff != "" {
+		t.Fatalf("defined objects different from expected: %s", diff)
+	}
+}
+
+func TestGeneratedObjects(t *testing.T) {
+	config := &Config{
+		Routers: []*RouterConfig{
+			{
+				MyASN: 65000,
+				Neighbors: []*NeighborConfig{
+					{
+						IPFamily: "ipv4",
+						ASN:      65001,
+						Addr:     "192.168.1.2",
+						RawConfig: ScopedRawConfig{
+							IPV4: "neighbor 192.168.1.2 route-map raw-in in",
+						},
+					},
+				},
+			},
+		},
+		BFDProfiles: []BFDProfile{{Name: "bfd"}},
+		ExtraConfig: "route-map raw-in permit 10",
+	}
+
+	generated, err := GeneratedObjects(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, o := range []NamedObject{
+		{Kind: RouteMap, Name: "192.168.1.2-in"},
+		{Kind: RouteMap, Name: "192.168.1.2-out"},
+		{Kind: IPPrefixList, Name: "192.168.1.2-pl-ipv4"},
+		{Kind: IPV6PrefixList, Name: "192.168.1.2-inpl-ipv4"},
+		{Kind: BFDProfileObject, Name: "bfd"},
+	} {
+		if !generated[o] {
+			t.Fatalf("expecting %s %s to be generated, got %v", o.Kind, o.Name, generated)
+		}
+	}
+	if generated[NamedObject{Kind: RouteMap, Name: "raw-in"}] {
+		t.Fatalf("not expecting the objects of the raw config among the generated ones")
+	}
+}
@@ -23,6 +23,7 @@ type Neighbor struct {
 	Port           int
 	RemoteRouterID string
 	MsgStats       MessageStats
+	AdminShutdown  bool
 }
 
 type Route struct {
@@ -43,6 +44,7 @@ type FRRNeighbor struct {
 	PortForeign       int          `json:"portForeign"`
 	MsgStats          MessageStats `json:"messageStats"`
 	VRFName           string       `json:"vrf"`
+	AdminShutDown     bool         `json:"adminShutDown"`
 	AddressFamilyInfo map[string]struct {
 		SentPrefixCounter     int `json:"sentPrefixCounter"`
 		AcceptedPrefixCounter int `json:"acceptedPrefixCounter"`
@@ -141,6 +143,7 @@ func ParseNeighbour(vtyshRes string) (*Neighbor, error) {
 			Port:           n.PortForeign,
 			RemoteRouterID: n.RemoteRouterID,
 			MsgStats:       n.MsgStats,
+			AdminShutdown:  n.AdminShutDown,
 		}, nil
 	}
 	return nil, errors.New("no peers were returned")
@@ -181,6 +184,7 @@ func ParseNeighbours(vtyshRes string) ([]*Neighbor, error) {
 			Port:           n.PortForeign,
 			RemoteRouterID: n.RemoteRouterID,
 			MsgStats:       n.MsgStats,
+			AdminShutdown:  n.AdminShutDown,
 		})
 	}
 	return res, nil
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// persistConfig stores the given configuration in the persisted config file, so that
+// it can be applied at startup, before the daemon is able to reach the API server.
+// The passwords of the neighbors are not stored, as they come from secrets.
+func (f *FRR) persistConfig(config *Config, l log.Logger) {
+	if f.persistedConfigFile == "" || config == f.lastPersistedConfig {
+		return
+	}
+	if err := writePersistedConfig(f.persistedConfigFile, config); err != nil {
+		level.Error(l).Log("op", "persist config", "error", err, "file", f.persistedConfigFile)
+		return
+	}
+	f.lastPersistedConfig = config
+}
+
+// applyPersistedConfig applies the configuration stored in the persisted
+// config file, if any.
+func (f *FRR) applyPersistedConfig(l log.Logger) {
+	if f.persistedConfigFile == "" {
+		return
+	}
+	config, err := readPersistedConfig(f.persistedConfigFile)
+	if errors.Is(err, os.ErrNotExist) {
+		level.Info(l).Log("op", "startup", "msg", "no persisted config to apply", "file", f.persistedConfigFile)
+		return
+	}
+	if err != nil {
+		level.Error(l).Log("op", "startup", "error", err, "file", f.persistedConfigFile, "msg", "ignoring the persisted config")
+		return
+	}
+	level.Info(l).Log("op", "startup", "msg", "applying the persisted config", "file", f.persistedConfigFile)
+	if err := f.ApplyConfig(config); err != nil {
+		level.Error(l).Log("op", "startup", "error", err, "msg", "failed to apply the persisted config")
+	}
+}
+
+func writePersistedConfig(path string, config *Config) error {
+	toPersist := *config
+	toPersist.Routers = make([]*RouterConfig, 0, len(config.Routers))
+	for _, r := range config.Routers {
+		router := *r
+		router.Neighbors = make([]*NeighborConfig, 0, len(r.Neighbors))
+		for _, n := range r.Neighbors {
+			neighbor := *n
+			neighbor.Password = ""
+			router.Neighbors = append(router.Neighbors, &neighbor)
+		}
+		toPersist.Routers = append(toPersist.Routers, &router)
+	}
+
+	data, err := json.Marshal(toPersist)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	// The file is replaced atomically, so a crash never leaves it partially written.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func readPersistedConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	res := &Config{}
+	if err := json.Unmarshal(data, res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+	return res, nil
+}
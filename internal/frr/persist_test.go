@@ -0,0 +1,105 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/google/go-cmp/cmp"
+	"github.com/metallb/frr-k8s/internal/ipfamily"
+	"github.com/metallb/frr-k8s/internal/logging"
+	"github.com/metallb/frr-k8s/internal/reloader"
+)
+
+func TestPersistConfig(t *testing.T) {
+	t.Setenv("FRR_CONFIG_FILE", filepath.Join(t.TempDir(), "frr.conf"))
+	oldReload := reloadConfig
+	defer func() { reloadConfig = oldReload }()
+	reloadConfig = func() (reloader.Response, error) {
+		return reloader.Response{Result: reloader.ResultSuccess}, nil
+	}
+
+	persisted := filepath.Join(t.TempDir(), "frr-k8s", "config.json")
+	frr := &FRR{persistedConfigFile: persisted}
+	config := &Config{
+		Hostname: "host",
+		Routers: []*RouterConfig{
+			{
+				MyASN: 65000,
+				Neighbors: []*NeighborConfig{
+					{
+						IPFamily: ipfamily.IPv4,
+						Name:     "65001@192.168.1.2",
+						ASN:      65001,
+						Addr:     "192.168.1.2",
+						Password: "secret",
+					},
+				},
+			},
+		},
+	}
+	if err := frr.reload(config, log.NewNopLogger()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	read, err := readPersistedConfig(persisted)
+	if err != nil {
+		t.Fatalf("failed to read the persisted config: %v", err)
+	}
+	expected := &Config{
+		Hostname: "host",
+		Routers: []*RouterConfig{
+			{
+				MyASN: 65000,
+				Neighbors: []*NeighborConfig{
+					{
+						IPFamily: ipfamily.IPv4,
+						Name:     "65001@192.168.1.2",
+						ASN:      65001,
+						Addr:     "192.168.1.2",
+					},
+				},
+			},
+		},
+	}
+	if !cmp.Equal(expected, read) {
+		t.Fatalf("unexpected persisted config (-want +got):\n%s", cmp.Diff(expected, read))
+	}
+	if config.Routers[0].Neighbors[0].Password != "secret" {
+		t.Fatalf("the applied config was modified")
+	}
+}
+
+func TestPersistedConfigAtStartup(t *testing.T) {
+	testSetup(t)
+	persisted := filepath.Join(t.TempDir(), "config.json")
+	config := &Config{
+		Routers: []*RouterConfig{
+			{
+				MyASN: 65000,
+				Neighbors: []*NeighborConfig{
+					{
+						IPFamily: ipfamily.IPv4,
+						Name:     "65001@192.168.1.2",
+						ASN:      65001,
+						Addr:     "192.168.1.2",
+						Password: "secret",
+					},
+				},
+				IPV4Prefixes: []string{"192.169.1.0/24"},
+			},
+		},
+	}
+	if err := writePersistedConfig(persisted, config); err != nil {
+		t.Fatalf("failed to persist the config: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	NewFRR(ctx, emptyCB, log.NewNopLogger(), logging.LevelInfo, Options{PersistedConfigFile: persisted})
+
+	testCheckConfigFile(t)
+}
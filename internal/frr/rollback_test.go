@@ -0,0 +1,118 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/google/go-cmp/cmp"
+	"github.com/metallb/frr-k8s/internal/reloader"
+)
+
+func TestRollback(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "frr.conf")
+	t.Setenv("FRR_CONFIG_FILE", configFile)
+
+	oldReload := reloadConfig
+	defer func() { reloadConfig = oldReload }()
+	reloaded := []string{}
+	reloadConfig = func() (reloader.Response, error) {
+		content, err := os.ReadFile(configFile)
+		if err != nil {
+			t.Fatalf("failed to read the config file: %v", err)
+		}
+		for _, h := range []string{"good", "broken", "new"} {
+			if strings.Contains(string(content), "hostname "+h) {
+				reloaded = append(reloaded, h)
+			}
+		}
+		if strings.Contains(string(content), "hostname broken") {
+			return reloader.Response{Result: reloader.ResultFailure, Error: "invalid configuration"}, nil
+		}
+		return reloader.Response{Result: reloader.ResultSuccess}, nil
+	}
+
+	statusChanges := 0
+	frr := &FRR{onStatusChanged: func() { statusChanges++ }}
+	good, broken, newConfig := &Config{Hostname: "good"}, &Config{Hostname: "broken"}, &Config{Hostname: "new"}
+
+	if err := frr.reload(good, log.NewNopLogger()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < rollbackAfterFailures-1; i++ {
+		if err := frr.reload(broken, log.NewNopLogger()); err == nil {
+			t.Fatalf("expecting error at attempt %d, got nil", i)
+		}
+		if frr.GetStatus().RolledBack {
+			t.Fatalf("not expecting to be rolled back at attempt %d", i)
+		}
+	}
+	if err := frr.reload(broken, log.NewNopLogger()); err != nil {
+		t.Fatalf("expecting no error after rolling back, got %v", err)
+	}
+	status := frr.GetStatus()
+	if !status.RolledBack {
+		t.Fatalf("expecting to be rolled back")
+	}
+	if !strings.Contains(status.LastReloadResult, "rolled back") || !strings.Contains(status.LastReloadResult, "invalid configuration") {
+		t.Fatalf("unexpected last reload result %q", status.LastReloadResult)
+	}
+	if statusChanges == 0 {
+		t.Fatalf("expecting the rollback to be notified")
+	}
+	if last := reloaded[len(reloaded)-1]; last != "good" {
+		t.Fatalf("expecting the good config to be reloaded last, got %s", last)
+	}
+
+	statusChanges = 0
+	if err := frr.reload(newConfig, log.NewNopLogger()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if frr.GetStatus().RolledBack {
+		t.Fatalf("not expecting to be rolled back after applying a new config")
+	}
+	if statusChanges == 0 {
+		t.Fatalf("expecting the end of the rollback to be notified")
+	}
+
+	history := frr.GetStatus().History
+	triggers := []ReloadTrigger{}
+	for _, a := range history {
+		triggers = append(triggers, a.Trigger)
+	}
+	expectedTriggers := []ReloadTrigger{TriggerConfigChange, TriggerConfigChange}
+	for i := 1; i < rollbackAfterFailures; i++ {
+		expectedTriggers = append(expectedTriggers, TriggerRetry)
+	}
+	expectedTriggers = append(expectedTriggers, TriggerRollback, TriggerConfigChange)
+	if diff := cmp.Diff(expectedTriggers, triggers); diff != "" {
+		t.Fatalf("reload triggers different from expected: %s", diff)
+	}
+	if history[1].Result == ReloadSuccess || history[len(history)-1].Result != ReloadSuccess {
+		t.Fatalf("unexpected reload results in history %v", history)
+	}
+}
+
+func TestNoRollbackWithoutGoodConfig(t *testing.T) {
+	t.Setenv("FRR_CONFIG_FILE", filepath.Join(t.TempDir(), "frr.conf"))
+
+	oldReload := reloadConfig
+	defer func() { reloadConfig = oldReload }()
+	reloadConfig = func() (reloader.Response, error) {
+		return reloader.Response{Result: reloader.ResultFailure, Error: "invalid configuration"}, nil
+	}
+
+	frr := &FRR{}
+	for i := 0; i < rollbackAfterFailures+1; i++ {
+		if err := frr.reload(&Config{Hostname: "broken"}, log.NewNopLogger()); err == nil {
+			t.Fatalf("expecting error at attempt %d, got nil", i)
+		}
+	}
+	if frr.GetStatus().RolledBack {
+		t.Fatalf("not expecting to be rolled back without a good config")
+	}
+}
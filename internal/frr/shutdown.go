@@ -0,0 +1,93 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// ShutdownMode is the way the advertised routes are handled when
+// the daemon shuts down.
+type ShutdownMode string
+
+const (
+	// ShutdownDisabled leaves the sessions to be torn down when FRR stops.
+	ShutdownDisabled ShutdownMode = ""
+	// ShutdownGraceful enables bgp graceful-shutdown, tagging the advertised routes
+	// with the GRACEFUL_SHUTDOWN community (RFC 8326).
+	ShutdownGraceful ShutdownMode = "graceful-shutdown"
+	// ShutdownWithdraw withdraws the advertised routes.
+	ShutdownWithdraw ShutdownMode = "withdraw"
+)
+
+// defaultShutdownDrainPeriod is the default time the peers are given to move the
+// traffic away from the node on shutdown.
+const defaultShutdownDrainPeriod = 30 * time.Second
+
+// ParseShutdownMode validates the given shutdown mode.
+func ParseShutdownMode(mode string) (ShutdownMode, error) {
+	switch m := ShutdownMode(mode); m {
+	case ShutdownDisabled, ShutdownGraceful, ShutdownWithdraw:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid shutdown mode %s, must be one of [%s, %s]", mode, ShutdownGraceful, ShutdownWithdraw)
+}
+
+// drainMode returns the drain mode the advertised routes are handled with on shutdown.
+func (m ShutdownMode) drainMode() DrainMode {
+	switch m {
+	case ShutdownGraceful:
+		return DrainGracefulShutdown
+	case ShutdownWithdraw:
+		return DrainWithdraw
+	}
+	return DrainDisabled
+}
+
+// ShutdownDone returns a channel that is closed once the routes are drained
+// after the context passed to NewFRR is done, and the daemon can exit.
+func (f *FRR) ShutdownDone() <-chan struct{} {
+	return f.shutdownDone
+}
+
+// drainOnShutdown waits for the given context to be done, then applies the last good
+// configuration changed according to the given mode, and waits for the drain period
+// so that the peers move the traffic away from the node before FRR stops.
+func (f *FRR) drainOnShutdown(ctx context.Context, mode ShutdownMode, drainPeriod time.Duration, l log.Logger) {
+	defer close(f.shutdownDone)
+	<-ctx.Done()
+	if mode == ShutdownDisabled {
+		return
+	}
+
+	if !f.drain(mode, l) {
+		return
+	}
+	level.Info(l).Log("op", "shutdown", "msg", "waiting for the peers to converge", "period", drainPeriod)
+	time.Sleep(drainPeriod)
+}
+
+// drain applies the last good configuration drained according to the given mode,
+// returning true if it was applied. The lock is held only while reloading, to wait
+// for an ongoing reload to complete: the reloads requested after the context is done
+// are skipped by the debouncer, so the drained configuration stays in place.
+func (f *FRR) drain(mode ShutdownMode, l log.Logger) bool {
+	f.reloading.Lock()
+	defer f.reloading.Unlock()
+	if f.lastGoodConfig == nil {
+		level.Info(l).Log("op", "shutdown", "msg", "no configuration to drain")
+		return false
+	}
+
+	level.Info(l).Log("op", "shutdown", "msg", "draining the advertised routes", "mode", mode)
+	if _, err := generateAndReloadConfigFile(DrainedConfig(f.lastGoodConfig, mode.drainMode()), l); err != nil {
+		level.Error(l).Log("op", "shutdown", "error", err, "msg", "failed to drain the advertised routes")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+)
+
+func TestDrainOnShutdown(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "frr.conf")
+	t.Setenv("FRR_CONFIG_FILE", configFile)
+
+	frr := &FRR{
+		shutdownDone: make(chan struct{}),
+		lastGoodConfig: &Config{
+			Hostname: "host",
+			Routers:  []*RouterConfig{{MyASN: 65000}},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go frr.drainOnShutdown(ctx, ShutdownGraceful, 200*time.Millisecond, log.NewNopLogger())
+
+	select {
+	case <-frr.ShutdownDone():
+		t.Fatalf("shutdown done before the context")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel()
+	start := time.Now()
+	// The reloads are not blocked while waiting for the peers to converge.
+	locked := false
+	for !locked && time.Since(start) < 100*time.Millisecond {
+		time.Sleep(5 * time.Millisecond)
+		if _, err := os.Stat(configFile); err == nil {
+			locked = frr.reloading.TryLock()
+		}
+	}
+	if !locked {
+		t.Fatalf("expecting the reload lock to be released during the drain period")
+	}
+	frr.reloading.Unlock()
+
+	select {
+	case <-frr.ShutdownDone():
+	case <-time.After(time.Second):
+		t.Fatalf("shutdown not done")
+	}
+	if time.Since(start) < 200*time.Millisecond {
+		t.Fatalf("expecting to wait for the drain period")
+	}
+
+	rendered, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("failed to read the config file: %v", err)
+	}
+	if !strings.Contains(string(rendered), "bgp graceful-shutdown") {
+		t.Fatalf("expecting graceful-shutdown in the drained config, got:\n%s", rendered)
+	}
+}
+
+func TestParseShutdownMode(t *testing.T) {
+	if _, err := ParseShutdownMode("withdraw"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ParseShutdownMode("foo"); err == nil {
+		t.Fatalf("expecting error, got nil")
+	}
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	Namespace = "frrk8s"
+	Subsystem = "frr"
+
+	rollbacks = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: Subsystem,
+		Name:      "rollbacks_total",
+		Help:      "Number of times FRR was reverted to the last good configuration because the latest one failed to reload.",
+	})
+
+	rolledBack = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: Subsystem,
+		Name:      "rolled_back_bool",
+		Help:      "1 if FRR is running with the last good configuration, because the latest one failed to reload.",
+	})
+
+	configDrifted = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: Subsystem,
+		Name:      "config_drift_bool",
+		Help:      "1 if the running configuration of FRR differs from the one rendered out of the FRRConfigurations.",
+	})
+
+	driftHeals = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: Subsystem,
+		Name:      "drift_heals_total",
+		Help:      "Number of times the rendered configuration was re-applied because the running one drifted from it.",
+	})
+
+	reloadBackoff = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: Subsystem,
+		Name:      "reload_retry_backoff_seconds",
+		Help:      "The interval the failed reload is going to be retried after, 0 if the last reload succeeded.",
+	})
+
+	reloadDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: Subsystem,
+		Name:      "reload_duration_seconds",
+		Help:      "Time taken to render and reload the configuration of FRR.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	})
+
+	configToApplied = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: Subsystem,
+		Name:      "config_to_applied_seconds",
+		Help:      "Time from when a new configuration is produced out of the FRRConfigurations to when it is applied to FRR.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(rollbacks, rolledBack, configDrifted, driftHeals, reloadBackoff, reloadDuration, configToApplied)
+}
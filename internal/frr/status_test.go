@@ -0,0 +1,52 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/metallb/frr-k8s/internal/logging"
+)
+
+func TestStatusChangedOnFileUpdate(t *testing.T) {
+	dir := t.TempDir()
+	oldStatus, oldRunning, oldLastError, oldInterval := statusFileName, runningConfig, lastAppliedResult, statusPollInterval
+	defer func() {
+		statusFileName, runningConfig, lastAppliedResult, statusPollInterval = oldStatus, oldRunning, oldLastError, oldInterval
+	}()
+	statusFileName = filepath.Join(dir, ".status")
+	runningConfig = filepath.Join(dir, "running-config")
+	lastAppliedResult = filepath.Join(dir, "last-error")
+	// Making sure the status is not fetched by polling.
+	statusPollInterval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 1)
+	frr := NewFRR(ctx, func() { changed <- struct{}{} }, log.NewNopLogger(), logging.LevelInfo, Options{})
+
+	if err := os.WriteFile(runningConfig, []byte("router bgp 65000"), 0644); err != nil {
+		t.Fatalf("failed to write the running config: %v", err)
+	}
+	if err := os.WriteFile(statusFileName, []byte("1 success"), 0644); err != nil {
+		t.Fatalf("failed to write the status: %v", err)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("status change not notified")
+	}
+	status := frr.GetStatus()
+	if status.LastReloadResult != ReloadSuccess {
+		t.Fatalf("expecting last reload result %s, got %s", ReloadSuccess, status.LastReloadResult)
+	}
+	if status.Current != "router bgp 65000" {
+		t.Fatalf("expecting running config to be updated, got %s", status.Current)
+	}
+}
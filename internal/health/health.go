@@ -0,0 +1,191 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type ProbeType string
+
+const (
+	HTTPProbe ProbeType = "http"
+	TCPProbe  ProbeType = "tcp"
+)
+
+// Probe is a check periodically run against a given endpoint.
+type Probe struct {
+	Type             ProbeType
+	Host             string
+	Port             int32
+	Path             string
+	Period           time.Duration
+	Timeout          time.Duration
+	FailureThreshold int
+}
+
+// Key identifies the probe. Two probes with the same parameters share the same key.
+func (p Probe) Key() string {
+	return fmt.Sprintf("%s://%s%s|%s|%s|%d", p.Type, net.JoinHostPort(p.Host, strconv.Itoa(int(p.Port))), p.Path,
+		p.Period, p.Timeout, p.FailureThreshold)
+}
+
+// Prober runs a set of probes and notifies when their result changes.
+type Prober struct {
+	sync.Mutex
+	ctx      context.Context
+	running  map[string]*probeState
+	onChange func()
+	logger   log.Logger
+}
+
+type probeState struct {
+	probe    Probe
+	cancel   context.CancelFunc
+	healthy  bool
+	failures int
+}
+
+// NewProber returns a new prober. onChange is called every time a probe
+// changes from healthy to unhealthy or vice versa.
+func NewProber(ctx context.Context, onChange func(), l log.Logger) *Prober {
+	return &Prober{
+		ctx:      ctx,
+		running:  map[string]*probeState{},
+		onChange: onChange,
+		logger:   l,
+	}
+}
+
+// Update replaces the set of probes to run with the given one, and returns the keys
+// of the probes currently healthy. A newly added probe is unhealthy until its first success.
+func (p *Prober) Update(probes []Probe) sets.Set[string] {
+	p.Lock()
+	defer p.Unlock()
+
+	toRun := map[string]Probe{}
+	for _, probe := range probes {
+		toRun[probe.Key()] = probe
+	}
+
+	for key, state := range p.running {
+		if _, ok := toRun[key]; ok {
+			continue
+		}
+		level.Debug(p.logger).Log("op", "probe", "action", "stop", "probe", key)
+		state.cancel()
+		delete(p.running, key)
+	}
+
+	for key, probe := range toRun {
+		if _, ok := p.running[key]; ok {
+			continue
+		}
+		level.Debug(p.logger).Log("op", "probe", "action", "start", "probe", key)
+		ctx, cancel := context.WithCancel(p.ctx)
+		state := &probeState{probe: probe, cancel: cancel}
+		p.running[key] = state
+		go p.run(ctx, state)
+	}
+
+	res := sets.New[string]()
+	for key, state := range p.running {
+		if state.healthy {
+			res.Insert(key)
+		}
+	}
+	return res
+}
+
+func (p *Prober) run(ctx context.Context, state *probeState) {
+	probe := state.probe
+	ticker := time.NewTicker(probe.Period)
+	defer ticker.Stop()
+
+	for {
+		err := check(ctx, probe)
+		if ctx.Err() != nil {
+			return
+		}
+		if p.setResult(state, err) {
+			level.Info(p.logger).Log("op", "probe", "probe", probe.Key(), "healthy", err == nil, "error", err)
+			p.onChange()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// setResult records the result of a probe attempt, returning true if
+// the probe changed its status.
+func (p *Prober) setResult(state *probeState, err error) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.running[state.probe.Key()] != state {
+		return false
+	}
+	if err == nil {
+		state.failures = 0
+		if state.healthy {
+			return false
+		}
+		state.healthy = true
+		return true
+	}
+
+	state.failures++
+	if !state.healthy {
+		return false
+	}
+	if state.failures < state.probe.FailureThreshold {
+		return false
+	}
+	state.healthy = false
+	return true
+}
+
+func check(ctx context.Context, probe Probe) error {
+	ctx, cancel := context.WithTimeout(ctx, probe.Timeout)
+	defer cancel()
+
+	address := net.JoinHostPort(probe.Host, strconv.Itoa(int(probe.Port)))
+	switch probe.Type {
+	case TCPProbe:
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case HTTPProbe:
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+address+probe.Path, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown probe type %s", probe.Type)
+}
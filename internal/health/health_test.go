@@ -0,0 +1,83 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package health
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+)
+
+func TestProber(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() || r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	host, port := hostPort(t, server.Listener.Addr())
+
+	changed := make(chan struct{}, 10)
+	prober := NewProber(ctx, func() { changed <- struct{}{} }, log.NewNopLogger())
+
+	httpProbe := Probe{Type: HTTPProbe, Host: host, Port: port, Path: "/healthz", Period: 10 * time.Millisecond, Timeout: time.Second, FailureThreshold: 2}
+	wrongPath := Probe{Type: HTTPProbe, Host: host, Port: port, Path: "/other", Period: 10 * time.Millisecond, Timeout: time.Second, FailureThreshold: 2}
+	tcpProbe := Probe{Type: TCPProbe, Host: host, Port: port, Period: 10 * time.Millisecond, Timeout: time.Second, FailureThreshold: 1}
+
+	healthy := prober.Update([]Probe{httpProbe, wrongPath, tcpProbe})
+	if healthy.Len() != 0 {
+		t.Fatalf("expected no healthy probes before the first run, got %v", healthy)
+	}
+
+	waitFor(t, changed, 2)
+	healthy = prober.Update([]Probe{httpProbe, wrongPath, tcpProbe})
+	if !healthy.Has(httpProbe.Key()) || !healthy.Has(tcpProbe.Key()) || healthy.Has(wrongPath.Key()) {
+		t.Fatalf("unexpected healthy probes %v", healthy)
+	}
+
+	failing.Store(true)
+	waitFor(t, changed, 1)
+	healthy = prober.Update([]Probe{httpProbe, tcpProbe})
+	if healthy.Has(httpProbe.Key()) || !healthy.Has(tcpProbe.Key()) {
+		t.Fatalf("unexpected healthy probes %v", healthy)
+	}
+
+	healthy = prober.Update([]Probe{})
+	if healthy.Len() != 0 {
+		t.Fatalf("expected no healthy probes after removing them, got %v", healthy)
+	}
+}
+
+func hostPort(t *testing.T, addr net.Addr) (string, int32) {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		t.Fatalf("failed to parse address %s: %v", addr, err)
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("failed to parse port %s: %v", port, err)
+	}
+	return host, int32(p)
+}
+
+func waitFor(t *testing.T, changed chan struct{}, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		select {
+		case <-changed:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for the probes to change")
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package reloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
+
+// Client sends the reload requests to the reloader listening on a unix socket.
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient returns a client talking to the reloader listening on the given socket.
+func NewClient(socket string) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+}
+
+// Reload asks the reloader to apply the configuration file and waits for the result.
+// An error is returned only if the request could not be served, the outcome of the
+// reload is part of the response.
+func (c *Client) Reload(ctx context.Context) (Response, error) {
+	// The host is ignored, as the connection goes through the unix socket.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://reloader"+ReloadPath, nil)
+	if err != nil {
+		return Response{}, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to send the reload request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return Response{}, fmt.Errorf("reload request failed with status %d: %s", resp.StatusCode, body)
+	}
+	var res Response
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return Response{}, fmt.Errorf("failed to decode the reload response: %w", err)
+	}
+	return res, nil
+}
+
+// RunningConfig returns the configuration FRR is currently running with.
+func (c *Client) RunningConfig(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://reloader"+RunningConfigPath, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request the running config: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the running config: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("running config request failed with status %d: %s", resp.StatusCode, body)
+	}
+	return string(body), nil
+}
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier:Apache-2.0
+
+// Package reloader implements the api used by the daemon to ask the reloader
+// running in the FRR container to apply the configuration file the daemon
+// rendered on the shared volume.
+package reloader
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// ReloadPath is the path of the reload endpoint.
+	ReloadPath = "/reload"
+	// RunningConfigPath is the path of the endpoint returning the running configuration.
+	RunningConfigPath = "/running-config"
+
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Response is the result of a reload request.
+type Response struct {
+	// Result is either success or failure.
+	Result string `json:"result"`
+	// Time is the time the reload completed at.
+	Time time.Time `json:"time"`
+	// Duration is the time it took to check and apply the configuration.
+	Duration time.Duration `json:"duration"`
+	// Diff contains the lines removed from and added to the running configuration.
+	Diff string `json:"diff,omitempty"`
+	// Stderr is the error output of the reload.
+	Stderr string `json:"stderr,omitempty"`
+	// Error describes why the reload failed.
+	Error string `json:"error,omitempty"`
+}
+
+// LinesChanged returns the number of lines added to and removed from the running
+// configuration, as reported in the diff.
+func (r Response) LinesChanged() (int, int) {
+	added, removed := 0, 0
+	var counter *int
+	for _, line := range strings.Split(r.Diff, "\n") {
+		switch strings.TrimSpace(line) {
+		case "Lines To Add":
+			counter = &added
+			continue
+		case "Lines To Delete":
+			counter = &removed
+			continue
+		case "":
+			continue
+		}
+		if counter == nil || strings.Trim(line, "=") == "" {
+			continue
+		}
+		*counter++
+	}
+	return added, removed
+}
+
+var passwordRegexp = regexp.MustCompile(`password.*`)
+
+// retractPasswords hides the neighbor passwords contained in the given output.
+func retractPasswords(output string) string {
+	return passwordRegexp.ReplaceAllString(output, "password <retracted>")
+}
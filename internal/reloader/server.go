@@ -0,0 +1,174 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package reloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+const frrReload = "/usr/lib/frr/frr-reload.py"
+
+// reloadCommand runs frr-reload.py with the given arguments, returning its standard output
+// and error separately.
+var reloadCommand = func(args ...string) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("python3", append([]string{frrReload}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
+}
+
+// showRunningConfig returns the configuration FRR is currently running with.
+var showRunningConfig = func() (string, error) {
+	res, err := exec.Command("vtysh", "-c", "show running-config").Output()
+	return string(res), err
+}
+
+// Server serves the reload requests, applying the configuration file stored
+// in the shared volume. The outcome of each reload is also stored in the shared
+// volume in the form of status files.
+type Server struct {
+	sync.Mutex
+	configFile        string
+	runningConfigFile string
+	statusFile        string
+	lastErrorFile     string
+	logger            log.Logger
+}
+
+// NewServer returns a server reloading the configuration file contained
+// in the given shared volume.
+func NewServer(sharedVolume string, logger log.Logger) *Server {
+	return &Server{
+		configFile:        filepath.Join(sharedVolume, "frr.conf"),
+		runningConfigFile: filepath.Join(sharedVolume, "running-config"),
+		statusFile:        filepath.Join(sharedVolume, ".status"),
+		lastErrorFile:     filepath.Join(sharedVolume, "last-error"),
+		logger:            logger,
+	}
+}
+
+// Handler returns the http handler serving the reload requests.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(ReloadPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		res := s.Reload()
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			level.Error(s.logger).Log("op", "reload", "error", err, "cause", "encode response")
+		}
+	})
+	mux.HandleFunc(RunningConfigPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		running, err := showRunningConfig()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to fetch the running config: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		if _, err := w.Write([]byte(running)); err != nil {
+			level.Error(s.logger).Log("op", "running config", "error", err, "cause", "write response")
+		}
+	})
+	return mux
+}
+
+// Reload checks and applies the configuration file. Concurrent reloads are
+// serialized.
+func (s *Server) Reload() Response {
+	s.Lock()
+	defer s.Unlock()
+
+	level.Info(s.logger).Log("op", "reload", "file", s.configFile)
+	start := time.Now()
+	res := s.reload()
+	res.Time = time.Now()
+	res.Duration = res.Time.Sub(start)
+
+	if err := s.saveStatus(res); err != nil {
+		level.Error(s.logger).Log("op", "reload", "error", err, "cause", "save status")
+	}
+	if res.Result != ResultSuccess {
+		level.Error(s.logger).Log("op", "reload", "result", res.Result, "error", res.Error, "stderr", res.Stderr, "duration", res.Duration)
+		return res
+	}
+	level.Info(s.logger).Log("op", "reload", "result", res.Result, "duration", res.Duration)
+	return res
+}
+
+func (s *Server) reload() Response {
+	// The test mode prints the lines that are going to be removed and added,
+	// which is the diff the reload applies.
+	diff, stderr, err := reloadCommand("--test", "--stdout", s.configFile)
+	res := Response{
+		Diff:   retractPasswords(diff),
+		Stderr: retractPasswords(stderr),
+	}
+	if err != nil {
+		res.Result = ResultFailure
+		res.Error = fmt.Sprintf("invalid configuration: %v", err)
+		return res
+	}
+
+	_, stderr, err = reloadCommand("--reload", "--overwrite", "--stdout", s.configFile)
+	res.Stderr += retractPasswords(stderr)
+	if err != nil {
+		res.Result = ResultFailure
+		res.Error = fmt.Sprintf("failed to apply the configuration: %v", err)
+		return res
+	}
+	res.Result = ResultSuccess
+	return res
+}
+
+// saveStatus stores the outcome of the given reload, together with the
+// current running configuration, in the shared volume.
+// The status file is written last, as its change signals a new outcome is available.
+func (s *Server) saveStatus(res Response) error {
+	running, runningErr := showRunningConfig()
+	if runningErr == nil {
+		runningErr = os.WriteFile(s.runningConfigFile, []byte(running), 0644)
+	}
+
+	lastError := ""
+	if res.Result != ResultSuccess {
+		lastError = res.Stderr
+		if lastError == "" {
+			lastError = res.Error
+		}
+	}
+	if err := os.WriteFile(s.lastErrorFile, []byte(lastError), 0644); err != nil {
+		return err
+	}
+	// The status file is replaced atomically, so its watchers never read it partially written.
+	tmpStatusFile := s.statusFile + ".tmp"
+	if err := os.WriteFile(tmpStatusFile, []byte(fmt.Sprintf("%d %s", res.Time.UnixNano(), res.Result)), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpStatusFile, s.statusFile); err != nil {
+		return err
+	}
+	if runningErr != nil {
+		return fmt.Errorf("failed to save the running config: %w", runningErr)
+	}
+	return nil
+}
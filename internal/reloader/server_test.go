@@ -0,0 +1,143 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package reloader
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+)
+
+func TestReload(t *testing.T) {
+	tests := []struct {
+		name              string
+		testErr           error
+		reloadErr         error
+		stderr            string
+		expectedResult    string
+		expectedLastError string
+	}{
+		{
+			name:           "success",
+			expectedResult: ResultSuccess,
+		},
+		{
+			name:              "invalid config",
+			testErr:           errors.New("exit status 1"),
+			stderr:            "line 3: neighbor 192.168.1.2 password secret foo\n",
+			expectedResult:    ResultFailure,
+			expectedLastError: "line 3: neighbor 192.168.1.2 password <retracted>\n",
+		},
+		{
+			name:              "failed to apply",
+			reloadErr:         errors.New("exit status 1"),
+			expectedResult:    ResultFailure,
+			expectedLastError: "failed to apply the configuration: exit status 1",
+		},
+	}
+
+	oldReload, oldRunning := reloadCommand, showRunningConfig
+	defer func() { reloadCommand, showRunningConfig = oldReload, oldRunning }()
+	showRunningConfig = func() (string, error) {
+		return "running", nil
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sharedVolume := t.TempDir()
+			reloadCommand = func(args ...string) (string, string, error) {
+				if args[len(args)-1] != filepath.Join(sharedVolume, "frr.conf") {
+					t.Fatalf("unexpected file to reload %s", args[len(args)-1])
+				}
+				if args[0] == "--test" {
+					return "Lines To Add\n============\nrouter bgp 65000\n", test.stderr, test.testErr
+				}
+				return "", "", test.reloadErr
+			}
+
+			socket := filepath.Join(sharedVolume, "reloader.sock")
+			listener, err := net.Listen("unix", socket)
+			if err != nil {
+				t.Fatalf("failed to listen on %s: %v", socket, err)
+			}
+			srv := &http.Server{Handler: NewServer(sharedVolume, log.NewNopLogger()).Handler()}
+			go func() { _ = srv.Serve(listener) }()
+			defer srv.Close()
+
+			res, err := NewClient(socket).Reload(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Result != test.expectedResult {
+				t.Fatalf("expecting result %s, got %s (%s)", test.expectedResult, res.Result, res.Error)
+			}
+			if !strings.Contains(res.Diff, "router bgp 65000") {
+				t.Fatalf("expecting the diff in the response, got %q", res.Diff)
+			}
+			if strings.Contains(res.Stderr, "secret") {
+				t.Fatalf("expecting the password to be retracted, got %q", res.Stderr)
+			}
+
+			status, err := os.ReadFile(filepath.Join(sharedVolume, ".status"))
+			if err != nil {
+				t.Fatalf("failed to read the status file: %v", err)
+			}
+			if fields := strings.Fields(string(status)); len(fields) != 2 || fields[1] != test.expectedResult {
+				t.Fatalf("unexpected status file content %q", status)
+			}
+			lastError, err := os.ReadFile(filepath.Join(sharedVolume, "last-error"))
+			if err != nil {
+				t.Fatalf("failed to read the last error file: %v", err)
+			}
+			if string(lastError) != test.expectedLastError {
+				t.Fatalf("expecting last error %q, got %q", test.expectedLastError, lastError)
+			}
+			running, err := os.ReadFile(filepath.Join(sharedVolume, "running-config"))
+			if err != nil {
+				t.Fatalf("failed to read the running config file: %v", err)
+			}
+			if string(running) != "running" {
+				t.Fatalf("unexpected running config %q", running)
+			}
+		})
+	}
+}
+
+func TestReloadNoServer(t *testing.T) {
+	_, err := NewClient(filepath.Join(t.TempDir(), "reloader.sock")).Reload(context.Background())
+	if err == nil {
+		t.Fatalf("expecting error, got nil")
+	}
+}
+
+func TestRunningConfig(t *testing.T) {
+	oldRunning := showRunningConfig
+	defer func() { showRunningConfig = oldRunning }()
+	showRunningConfig = func() (string, error) {
+		return "router bgp 65000", nil
+	}
+
+	socket := filepath.Join(t.TempDir(), "reloader.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", socket, err)
+	}
+	srv := &http.Server{Handler: NewServer(t.TempDir(), log.NewNopLogger()).Handler()}
+	go func() { _ = srv.Serve(listener) }()
+	defer srv.Close()
+
+	running, err := NewClient(socket).RunningConfig(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if running != "router bgp 65000" {
+		t.Fatalf("unexpected running config %q", running)
+	}
+}